@@ -0,0 +1,91 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+)
+
+// StripANSI 原地移除 ANSI 转义序列(CSI 如 "\x1b[0;31m"，以及 ESC 后跟单个字符的序列)
+func StripANSI(b []byte) []byte {
+	i := bytes.IndexByte(b, 0x1b)
+	if i < 0 {
+		return b
+	}
+	n := i
+	for i < len(b) {
+		c := b[i]
+		if c != 0x1b {
+			b[n] = c
+			n++
+			i++
+			continue
+		}
+		i += ansiSeqLen(b[i:])
+	}
+	return b[:n]
+}
+
+// ansiSeqLen b 开头(0x1b)的转义序列的长度
+func ansiSeqLen(b []byte) int {
+	i := 1
+	if i < len(b) && b[i] == '[' {
+		// CSI: 参数字节 0x30-0x3F，中间字节 0x20-0x2F，结束字节 0x40-0x7E
+		i++
+		for i < len(b) && b[i] >= 0x20 && b[i] <= 0x3f {
+			i++
+		}
+		if i < len(b) && b[i] >= 0x40 && b[i] <= 0x7e {
+			i++
+		}
+	} else if i < len(b) {
+		i++
+	}
+	return i
+}
+
+// StripANSIString 移除字符串中的 ANSI 转义序列
+func StripANSIString(s string) string {
+	if strings.IndexByte(s, 0x1b) < 0 {
+		return s
+	}
+	return string(StripANSI([]byte(s)))
+}
+
+type stripANSIHandler struct {
+	handler IHandler
+	pool    sync.Pool
+}
+
+func (s *stripANSIHandler) Write(b []byte) (n int, err error) {
+	if bytes.IndexByte(b, 0x1b) < 0 {
+		return s.handler.Write(b)
+	}
+	buf := s.pool.Get().(*writePool)
+	buf.buffer = append(buf.buffer[:0], b...)
+	defer s.pool.Put(buf)
+	if _, err = s.handler.Write(StripANSI(buf.buffer)); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (s *stripANSIHandler) Close() (err error) {
+	return s.handler.Close()
+}
+
+func (s *stripANSIHandler) Unwrap() IHandler {
+	return s.handler
+}
+
+// NewStripANSIHandler 包装 handler，写入前移除 ANSI 转义序列
+func NewStripANSIHandler(handler IHandler) IHandler {
+	return &stripANSIHandler{
+		handler: handler,
+		pool: sync.Pool{
+			New: func() any {
+				return &writePool{buffer: make([]byte, 0, 1024)}
+			},
+		},
+	}
+}
@@ -0,0 +1,110 @@
+package log_test
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/ohayao/log/v2"
+)
+
+// discard 丢弃所有输出，只统计格式化的开销
+type discard struct{}
+
+func (discard) Write(b []byte) (int, error) { return len(b), nil }
+func (discard) Close() error                { return nil }
+
+func benchLog(b *testing.B, fn func()) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		fn()
+	}
+}
+
+func BenchmarkInfo(b *testing.B) {
+	logger := log.New(discard{}, log.WithColor(false))
+	benchLog(b, func() { logger.Info("hello world") })
+}
+
+func BenchmarkInfof(b *testing.B) {
+	logger := log.New(discard{}, log.WithColor(false))
+	benchLog(b, func() { logger.Infof("hello %s, it's %d", "world", 42) })
+}
+
+func BenchmarkErrorfCaller(b *testing.B) {
+	logger := log.New(discard{}, log.WithColor(false))
+	err := errors.New("connection refused")
+	benchLog(b, func() { logger.Errorf("query failed: %v", err) })
+}
+
+func BenchmarkWithInfo(b *testing.B) {
+	logger := log.New(discard{}, log.WithColor(false)).With("uid", 10086, "ip", "127.0.0.1")
+	benchLog(b, func() { logger.Info("login") })
+}
+
+func BenchmarkDebugDisabled(b *testing.B) {
+	logger := log.New(discard{}, log.WithMinLevel(log.LV_INFO))
+	benchLog(b, func() { logger.Debug("hello") })
+}
+
+func BenchmarkColorInfo(b *testing.B) {
+	logger := log.New(discard{}, log.WithColor(true))
+	benchLog(b, func() { logger.Info("hello world") })
+}
+
+func BenchmarkColorInfof(b *testing.B) {
+	logger := log.New(discard{}, log.WithColor(true))
+	benchLog(b, func() { logger.Infof("hello %s, it's %d", "world", 42) })
+}
+
+// BenchmarkFileContention 对比多核下互斥锁的文件 handler 与无锁异步 handler
+func BenchmarkFileContention(b *testing.B) {
+	dir := b.TempDir()
+	for _, procs := range []int{1, 8, 64} {
+		for _, async := range []bool{false, true} {
+			name := fmt.Sprintf("mutex/procs=%d", procs)
+			opts := []log.Option{log.WithColor(false)}
+			if async {
+				name = fmt.Sprintf("async/procs=%d", procs)
+				opts = append(opts, log.WithAsync(0))
+			}
+			b.Run(name, func(b *testing.B) {
+				defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(procs))
+				logger, err := log.NewFileLogger(filepath.Join(dir, fmt.Sprintf("%d_%t.log", procs, async)), 0, opts...)
+				if err != nil {
+					b.Fatal(err)
+				}
+				defer logger.Close()
+				b.ReportAllocs()
+				b.ResetTimer()
+				b.RunParallel(func(pb *testing.PB) {
+					for pb.Next() {
+						logger.Infof("hello %s, it's %d", "world", 42)
+					}
+				})
+				_ = logger.Flush()
+			})
+		}
+	}
+}
+
+func TestZeroAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocations are not stable with the race detector")
+	}
+	logger := log.New(discard{}, log.WithColor(false))
+	err := errors.New("connection refused")
+	cases := map[string]func(){
+		"Info":   func() { logger.Info("hello world") },
+		"Infof":  func() { logger.Infof("hello %s, it's %d", "world", 42) },
+		"Errorf": func() { logger.Errorf("query failed: %v", err) },
+	}
+	for name, fn := range cases {
+		fn()
+		if allocs := testing.AllocsPerRun(100, fn); allocs > 0 {
+			t.Errorf("%s: %v allocs/op, want 0", name, allocs)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package log
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// 从 caller 到调用方的固定层数: runtime.Callers -> caller -> output -> log -> Info -> 调用方
+//
+// 包级函数(log.Info)直接调用 DEFAULT.log，保持相同的层数
+const callerDepth = 5
+
+type callerInfo struct {
+	file string
+	line int
+	// 完整的函数名
+	fn string
+}
+
+// pc => *callerInfo，按 pc 分片加锁，key 不需要装箱
+const callerShards = 64
+
+type callerShard struct {
+	lock sync.RWMutex
+	m    map[uintptr]*callerInfo
+}
+
+var callerCache [callerShards]callerShard
+
+func callerShardOf(pc uintptr) *callerShard {
+	return &callerCache[(pc>>4)%callerShards]
+}
+
+func loadCaller(pc uintptr) (*callerInfo, bool) {
+	shard := callerShardOf(pc)
+	shard.lock.RLock()
+	info, ok := shard.m[pc]
+	shard.lock.RUnlock()
+	return info, ok
+}
+
+func storeCaller(pc uintptr, info *callerInfo) {
+	shard := callerShardOf(pc)
+	shard.lock.Lock()
+	if shard.m == nil {
+		shard.m = make(map[uintptr]*callerInfo)
+	}
+	shard.m[pc] = info
+	shard.lock.Unlock()
+}
+
+// caller 通过一次 runtime.Callers 获取调用方，并按 pc 缓存文件、行号及函数名
+func (l *Logger) caller() (file string, line int, fn string) {
+	var pcs [1]uintptr
+	if runtime.Callers(callerDepth+l.callerSkip, pcs[:]) < 1 {
+		return "?", 0, "?"
+	}
+	info, ok := resolveCaller(pcs[0])
+	if !ok || (l.callerSkip == 0 && strings.HasPrefix(info.fn, packagePrefix)) {
+		// 未预期的调用路径，退化为逐层查找
+		return WhoCalledMe()
+	}
+	return info.file, info.line, shortFuncName(info.fn)
+}
+
+// resolveCaller 解析 pc 并缓存，单独的函数避免 caller 中的 pcs 逃逸到堆上
+func resolveCaller(pc uintptr) (*callerInfo, bool) {
+	if info, ok := loadCaller(pc); ok {
+		return info, true
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	if frame.Function == "" {
+		return nil, false
+	}
+	info := &callerInfo{file: frame.File, line: frame.Line, fn: frame.Function}
+	storeCaller(pc, info)
+	return info, true
+}
+
+func shortFuncName(fn string) string {
+	if idx := strings.LastIndex(fn, "."); idx > -1 {
+		return fn[idx+1:]
+	}
+	return fn
+}
@@ -2,18 +2,58 @@ package log
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
 func ColorWrap(content any, colors ...COLOR_ENUM) string {
-	// 合并颜色
-	colorNumbers := make([]string, 0, len(colors)+1)
+	return string(appendColorize(nil, fmt.Sprint(content), colors))
+}
+
+// appendSGR 追加合并后的颜色，eg: "\x1b[0;31;1m"
+func appendSGR(b []byte, colors []COLOR_ENUM) []byte {
 	// 重置颜色
-	colorNumbers = append(colorNumbers, "0")
+	b = append(b, "\x1b[0"...)
 	for _, color := range colors {
-		number := strings.Replace(string(color), "\x1b[", "", -1)
-		number = strings.Replace(number, "m", "", -1)
-		colorNumbers = append(colorNumbers, number)
+		// 合并颜色
+		number := strings.TrimPrefix(string(color), "\x1b[")
+		number = strings.TrimSuffix(number, "m")
+		b = append(b, ';')
+		b = append(b, number...)
+	}
+	return append(b, 'm')
+}
+
+func appendColorize(b []byte, content string, colors []COLOR_ENUM) []byte {
+	b = appendSGR(b, colors)
+	b = append(b, content...)
+	return append(b, COLOR_CTRL_RESET...)
+}
+
+// colorize 颜色为空时原样返回
+func colorize(content string, colors []COLOR_ENUM) string {
+	if len(colors) == 0 {
+		return content
 	}
-	return fmt.Sprintf("\x1b[%sm%s%s", strings.Join(colorNumbers, ";"), fmt.Sprint(content), COLOR_CTRL_RESET)
+	return string(appendColorize(nil, content, colors))
+}
+
+// Color256 256色前景色，n 为调色板编号
+func Color256(n uint8) COLOR_ENUM {
+	return COLOR_ENUM("\x1b[38;5;" + strconv.Itoa(int(n)) + "m")
+}
+
+// BgColor256 256色背景色，n 为调色板编号
+func BgColor256(n uint8) COLOR_ENUM {
+	return COLOR_ENUM("\x1b[48;5;" + strconv.Itoa(int(n)) + "m")
+}
+
+// ColorRGB 24位真彩色前景色
+func ColorRGB(r, g, b uint8) COLOR_ENUM {
+	return COLOR_ENUM(fmt.Sprintf("\x1b[38;2;%d;%d;%dm", r, g, b))
+}
+
+// BgColorRGB 24位真彩色背景色
+func BgColorRGB(r, g, b uint8) COLOR_ENUM {
+	return COLOR_ENUM(fmt.Sprintf("\x1b[48;2;%d;%d;%dm", r, g, b))
 }
@@ -0,0 +1,50 @@
+package log
+
+import (
+	"os"
+)
+
+// shouldColor 判断 FLAG_COLOR_AUTO 模式下是否输出颜色
+//
+// 优先级: NO_COLOR > FORCE_COLOR > TERM=dumb > 是否为终端
+func shouldColor(handler IHandler) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if force := os.Getenv("FORCE_COLOR"); force != "" && force != "0" && force != "false" {
+		return true
+	}
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	f := handlerFile(baseHandler(handler))
+	if f == nil {
+		return false
+	}
+	return isTerminal(f)
+}
+
+func handlerFile(handler IHandler) *os.File {
+	switch h := handler.(type) {
+	case *terminalHandler:
+		return h.w
+	case *os.File:
+		return h
+	}
+	return nil
+}
+
+func (l *Logger) resolveColor() {
+	switch l.colorMode {
+	case FLAG_COLOR_ALWAYS:
+		l.enableColor = true
+	case FLAG_COLOR_NEVER:
+		l.enableColor = false
+	default:
+		l.enableColor = shouldColor(l.handler)
+	}
+	switch baseHandler(l.handler).(type) {
+	case *fileHandler, *fileRotateHandler:
+		l.enableColor = false
+	}
+}
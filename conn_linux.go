@@ -0,0 +1,28 @@
+//go:build linux
+
+package log
+
+import (
+	"net"
+	"syscall"
+)
+
+// connAlive 以非阻塞的 MSG_PEEK 检查流式连接是否已被对端关闭，
+// 避免断开后的第一条日志写入内核缓冲区后丢失
+func connAlive(conn net.Conn) bool {
+	sc, ok := conn.(syscall.Conn)
+	if !ok {
+		return true
+	}
+	raw, err := sc.SyscallConn()
+	if err != nil {
+		return true
+	}
+	alive := true
+	_ = raw.Control(func(fd uintptr) {
+		var b [1]byte
+		n, _, err := syscall.Recvfrom(int(fd), b[:], syscall.MSG_PEEK|syscall.MSG_DONTWAIT)
+		alive = n > 0 || err == syscall.EAGAIN || err == syscall.EWOULDBLOCK || err == syscall.EINTR
+	})
+	return alive
+}
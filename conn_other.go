@@ -0,0 +1,12 @@
+//go:build !linux
+
+package log
+
+import (
+	"net"
+)
+
+// connAlive 非 linux 平台不检查，依赖写入失败后重连
+func connAlive(conn net.Conn) bool {
+	return true
+}
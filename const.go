@@ -7,6 +7,20 @@ import (
 type (
 	FLAG_TIME int
 
+	FLAG_CALLER int
+
+	FLAG_COLOR int
+
+	FLAG_MULTILINE int
+
+	FLAG_SYSLOG int
+
+	SYSLOG_FACILITY int
+
+	FLAG_FRAMING int
+
+	FLAG_HTTP_FORMAT int
+
 	COLOR_ENUM string
 
 	lvAttr struct {
@@ -24,8 +38,97 @@ const (
 	FLAG_TIME_TIME FLAG_TIME = 2
 	// 日期时间: eg: 2022/01/01 15:04:05.000
 	FLAG_TIME_DATETIME FLAG_TIME = 3
-	// 时间戳
+	// 时间戳(毫秒)
 	FLAG_TIME_TIMESTAMP FLAG_TIME = 4
+	// RFC3339Nano: eg: 2022-01-01T15:04:05.999999999+08:00
+	FLAG_TIME_RFC3339NANO FLAG_TIME = 5
+	// 时间戳(微秒)
+	FLAG_TIME_TIMESTAMP_MICRO FLAG_TIME = 6
+	// 时间戳(纳秒)
+	FLAG_TIME_TIMESTAMP_NANO FLAG_TIME = 7
+	// 进程启动后经过的时间(单调时钟): eg: 12.345678s
+	FLAG_TIME_ELAPSED FLAG_TIME = 8
+	// 自定义格式，见 WithTimeLayout
+	FLAG_TIME_LAYOUT FLAG_TIME = 9
+)
+
+const (
+	// 仅文件名: eg: handler.go
+	FLAG_CALLER_BASE FLAG_CALLER = 0
+	// 包目录/文件名: eg: pkg/handler.go
+	FLAG_CALLER_PACKAGE FLAG_CALLER = 1
+	// 相对模块根目录: eg: internal/pkg/handler.go
+	FLAG_CALLER_MODULE FLAG_CALLER = 2
+	// 完整路径
+	FLAG_CALLER_FULL FLAG_CALLER = 3
+)
+
+const (
+	// 自动检测: 终端输出颜色，遵循 NO_COLOR / FORCE_COLOR / TERM=dumb
+	FLAG_COLOR_AUTO FLAG_COLOR = 0
+	// 总是输出颜色
+	FLAG_COLOR_ALWAYS FLAG_COLOR = 1
+	// 不输出颜色
+	FLAG_COLOR_NEVER FLAG_COLOR = 2
+)
+
+const (
+	// 原样输出换行
+	FLAG_MULTILINE_NONE FLAG_MULTILINE = 0
+	// 换行转义为 \n、反斜杠转义为 \\，一条记录只占一行
+	FLAG_MULTILINE_ESCAPE FLAG_MULTILINE = 1
+	// 续行以标记开头，eg: "  | "
+	FLAG_MULTILINE_INDENT FLAG_MULTILINE = 2
+)
+
+const (
+	// RFC 5424: <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID SD MSG
+	FLAG_SYSLOG_RFC5424 FLAG_SYSLOG = 0
+	// RFC 3164(BSD): <PRI>Mmm dd hh:mm:ss HOSTNAME TAG[PID]: MSG
+	FLAG_SYSLOG_RFC3164 FLAG_SYSLOG = 1
+)
+
+const (
+	// 每条日志以换行结尾
+	FLAG_FRAMING_NEWLINE FLAG_FRAMING = 0
+	// 每条日志前为 4 字节大端序的长度，不含结尾的换行
+	FLAG_FRAMING_LENGTH FLAG_FRAMING = 1
+)
+
+const (
+	// JSON 数组: [{"time":...,"level":...,"message":...}, ...]，time 的格式同 WithTimeStyle
+	FLAG_HTTP_JSON FLAG_HTTP_FORMAT = 0
+	// Loki push API: {"streams":[{"stream":{labels},"values":[["<ns>","<line>"], ...]}]}
+	FLAG_HTTP_LOKI FLAG_HTTP_FORMAT = 1
+	// Elasticsearch _bulk NDJSON，@timestamp 为 RFC3339Nano
+	FLAG_HTTP_ELASTIC FLAG_HTTP_FORMAT = 2
+)
+
+const (
+	SYSLOG_FACILITY_KERN SYSLOG_FACILITY = iota
+	SYSLOG_FACILITY_USER
+	SYSLOG_FACILITY_MAIL
+	SYSLOG_FACILITY_DAEMON
+	SYSLOG_FACILITY_AUTH
+	SYSLOG_FACILITY_SYSLOG
+	SYSLOG_FACILITY_LPR
+	SYSLOG_FACILITY_NEWS
+	SYSLOG_FACILITY_UUCP
+	SYSLOG_FACILITY_CRON
+	SYSLOG_FACILITY_AUTHPRIV
+	SYSLOG_FACILITY_FTP
+	_
+	_
+	_
+	_
+	SYSLOG_FACILITY_LOCAL0
+	SYSLOG_FACILITY_LOCAL1
+	SYSLOG_FACILITY_LOCAL2
+	SYSLOG_FACILITY_LOCAL3
+	SYSLOG_FACILITY_LOCAL4
+	SYSLOG_FACILITY_LOCAL5
+	SYSLOG_FACILITY_LOCAL6
+	SYSLOG_FACILITY_LOCAL7
 )
 
 const (
@@ -1,9 +1,15 @@
 package log
 
+import (
+	"fmt"
+	"os"
+)
+
+// 包级函数直接调用 DEFAULT.log / DEFAULT.logf，与 Logger 方法的调用层数一致，见 callerDepth
 var DEFAULT *Logger
 
 func init() {
-	DEFAULT = New(newTerminalHandler(nil), WithColor(true), WithMinLevel(LV_DEBUG), WithShortName(false), WithTimeStyle(FLAG_TIME_DATETIME))
+	DEFAULT = New(newTerminalHandler(nil), WithColorMode(FLAG_COLOR_AUTO), WithMinLevel(LV_DEBUG), WithShortName(false), WithTimeStyle(FLAG_TIME_DATETIME))
 }
 
 func UseOption(logger *Logger, opts ...Option) {
@@ -13,85 +19,125 @@ func UseOption(logger *Logger, opts ...Option) {
 }
 
 func Fatal(args ...any) {
-	DEFAULT.Fatal(args...)
+	DEFAULT.log(LV_FATAL, args...)
+	_ = DEFAULT.Flush()
+	os.Exit(1)
 }
 
 func Fatalf(format string, args ...any) {
-	DEFAULT.Fatalf(format, args...)
+	DEFAULT.logf(LV_FATAL, format, args...)
+	_ = DEFAULT.Flush()
+	os.Exit(1)
 }
 
 func Fatalln(args ...any) {
-	DEFAULT.Fatalln(args...)
+	DEFAULT.log(LV_FATAL, args...)
+	_ = DEFAULT.Flush()
+	os.Exit(1)
 }
 
 func Panic(args ...any) {
-	DEFAULT.Panic(args...)
+	DEFAULT.log(LV_PANIC, args...)
+	_ = DEFAULT.Flush()
+	panic(fmt.Errorf(fmt.Sprint(args...)))
 }
 
 func Panicf(format string, args ...any) {
-	DEFAULT.Panicf(format, args...)
+	DEFAULT.logf(LV_PANIC, format, args...)
+	_ = DEFAULT.Flush()
+	panic(fmt.Errorf(format, args...))
 }
 
 func Panicln(args ...any) {
-	DEFAULT.Panicln(args...)
+	DEFAULT.log(LV_PANIC, args...)
+	_ = DEFAULT.Flush()
+	panic(fmt.Errorf(fmt.Sprint(args...)))
 }
 
 func Print(args ...any) {
-	DEFAULT.Print(args...)
+	DEFAULT.log(LV_PRINT, args...)
 }
 
 func Printf(format string, args ...any) {
-	DEFAULT.Printf(format, args...)
+	DEFAULT.logf(LV_PRINT, format, args...)
 }
 
 func Println(args ...any) {
-	DEFAULT.Println(args...)
+	DEFAULT.log(LV_PRINT, args...)
 }
 
 func Info(args ...any) {
-	DEFAULT.Info(args...)
+	DEFAULT.log(LV_INFO, args...)
 }
 
 func Infof(format string, args ...any) {
-	DEFAULT.Infof(format, args...)
+	DEFAULT.logf(LV_INFO, format, args...)
 }
 
 func Infoln(args ...any) {
-	DEFAULT.Infoln(args...)
+	DEFAULT.log(LV_INFO, args...)
 }
 
 func Warn(args ...any) {
-	DEFAULT.Warn(args...)
+	DEFAULT.log(LV_WARN, args...)
 }
 
 func Warnf(format string, args ...any) {
-	DEFAULT.Warnf(format, args...)
+	DEFAULT.logf(LV_WARN, format, args...)
 }
 
 func Warnln(args ...any) {
-	DEFAULT.Warnln(args...)
+	DEFAULT.log(LV_WARN, args...)
 }
 
 func Error(args ...any) {
-	DEFAULT.Error(args...)
+	DEFAULT.log(LV_ERROR, args...)
 }
 
 func Errorf(format string, args ...any) {
-	DEFAULT.Errorf(format, args...)
+	DEFAULT.logf(LV_ERROR, format, args...)
 }
 
 func Errorln(args ...any) {
-	DEFAULT.Errorln(args...)
+	DEFAULT.log(LV_ERROR, args...)
 }
 
 func Debug(args ...any) {
-	DEFAULT.Debug(args...)
+	DEFAULT.log(LV_DEBUG, args...)
 }
 
 func Debugf(format string, args ...any) {
-	DEFAULT.Debugf(format, args...)
+	DEFAULT.logf(LV_DEBUG, format, args...)
 }
 
 func Debugln(args ...any) {
-	DEFAULT.Debugln(args...)
+	DEFAULT.log(LV_DEBUG, args...)
+}
+
+func Flush() error {
+	return DEFAULT.Flush()
+}
+
+func Enabled(level int) bool {
+	return DEFAULT.Enabled(level)
+}
+
+func PrintFn(fn func() string) {
+	DEFAULT.logFn(LV_PRINT, fn)
+}
+
+func InfoFn(fn func() string) {
+	DEFAULT.logFn(LV_INFO, fn)
+}
+
+func WarnFn(fn func() string) {
+	DEFAULT.logFn(LV_WARN, fn)
+}
+
+func ErrorFn(fn func() string) {
+	DEFAULT.logFn(LV_ERROR, fn)
+}
+
+func DebugFn(fn func() string) {
+	DEFAULT.logFn(LV_DEBUG, fn)
 }
@@ -0,0 +1,112 @@
+package log
+
+import (
+	"strconv"
+	"strings"
+)
+
+// 错误链展开的最大深度，避免循环引用
+const maxErrorChainDepth = 32
+
+func (l *Logger) withErrorChain(buf *writePool, args []any) {
+	if !l.errorChain {
+		return
+	}
+	for _, arg := range args {
+		if err, ok := arg.(error); ok {
+			buf.buffer = l.appendErrorChain(buf.buffer, err, 1)
+		}
+	}
+}
+
+func (l *Logger) appendErrorChain(b []byte, err error, depth int) []byte {
+	if depth > maxErrorChainDepth {
+		return b
+	}
+	for _, child := range unwrapErrors(err) {
+		if isNilError(child) {
+			continue
+		}
+		b = append(b, 0x0a)
+		b = append(b, strings.Repeat("    ", depth-1)...)
+		b = append(b, "  └─ "...)
+		text := errorText(child)
+		if l.redactor != nil {
+			text = l.redactor.RedactString(text)
+		}
+		if l.enableColor {
+			text = colorize(text, l.theme.Error)
+		}
+		b = append(b, text...)
+		b = l.appendErrorChain(b, child, depth+1)
+	}
+	return b
+}
+
+// withErrorTexts 将参数中 error 的包装链逐层追加到 buf.chain，供 RecordHandler 使用
+func (l *Logger) withErrorTexts(buf *writePool, args []any) {
+	if !l.errorChain {
+		return
+	}
+	for _, arg := range args {
+		if err, ok := arg.(error); ok {
+			buf.chain = l.appendErrorTexts(buf.chain, err, 1)
+		}
+	}
+}
+
+// appendErrorTexts 深度优先，跳过包装了多个错误的节点，内容去除颜色并脱敏
+func (l *Logger) appendErrorTexts(dst []string, err error, depth int) []string {
+	if depth > maxErrorChainDepth {
+		return dst
+	}
+	for _, child := range unwrapErrors(err) {
+		if isNilError(child) {
+			continue
+		}
+		if _, ok := child.(interface{ Unwrap() []error }); !ok {
+			text := StripANSIString(errorText(child))
+			if l.redactor != nil {
+				text = l.redactor.RedactString(text)
+			}
+			dst = append(dst, text)
+		}
+		dst = l.appendErrorTexts(dst, child, depth+1)
+	}
+	return dst
+}
+
+// unwrapErrors 获取被包装的错误，值为 nil 指针等的 error 不调用其方法，见 isNilError
+func unwrapErrors(err error) []error {
+	if isNilError(err) {
+		return nil
+	}
+	switch e := err.(type) {
+	case interface{ Unwrap() []error }:
+		return e.Unwrap()
+	case interface{ Unwrap() error }:
+		if u := e.Unwrap(); u != nil {
+			return []error{u}
+		}
+	}
+	return nil
+}
+
+// errorText 获取当前层的错误信息，去掉 fmt.Errorf("xxx: %w") 中被包装错误的部分
+//
+// 包装了多个错误的节点(如 errors.Join)的信息即各子错误的信息，只输出数量，由子节点展开
+func errorText(err error) string {
+	if isNilError(err) {
+		return "<nil>"
+	}
+	if e, ok := err.(interface{ Unwrap() []error }); ok {
+		return "(" + strconv.Itoa(len(e.Unwrap())) + " errors)"
+	}
+	text := err.Error()
+	if children := unwrapErrors(err); len(children) == 1 && !isNilError(children[0]) {
+		if trimmed := strings.TrimSuffix(text, ": "+children[0].Error()); trimmed != "" {
+			return trimmed
+		}
+	}
+	return text
+}
@@ -0,0 +1,32 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErrorChainJoin(t *testing.T) {
+	logger, h := newTestLogger(WithErrorChain(true))
+	err := fmt.Errorf("top: %w", errors.Join(errors.New("a"), fmt.Errorf("b: %w", errors.New("c"))))
+	logger.Error(err)
+	want := "top: a\nb: c\n" +
+		"  └─ (2 errors)\n" +
+		"      └─ a\n" +
+		"      └─ b\n" +
+		"          └─ c\n"
+	if got := h.String(); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestErrorChainTypedNil(t *testing.T) {
+	logger, h := newTestLogger(WithErrorChain(true))
+	var err *nilPointerError
+	logger.Error("x", err)
+	logger.Error(fmt.Errorf("outer: %w", err))
+	want := "x<nil>\nouter: <nil>\n"
+	if got := h.String(); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
@@ -8,10 +8,20 @@ import (
 )
 
 func main() {
+	testRedact()
 	testDefault()
 	testTerminal()
 }
 
+func testRedact() {
+	logger := log.NewTerminalLogger(nil,
+		log.WithRedactor(log.NewRedactor(nil, log.REDACT_JWT, log.REDACT_CARD)),
+	)
+	// Authorization: *** password=*** card=***
+	logger.Info("Authorization: Bearer abc.def.ghi password=123456 card=4111 1111 1111 1111")
+	logger.With("token", "abc.def.ghi").Info("login")
+}
+
 func testDefault() {
 	log.UseOption(log.DEFAULT,
 		log.WithColor(true),
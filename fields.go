@@ -0,0 +1,141 @@
+package log
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type Field struct {
+	Key   string
+	Value any
+}
+
+// With 返回附加了字段的子 Logger，参数为 key, value 交替
+//
+// eg: logger.With("uid", 1, "ip", "127.0.0.1").Info("login")
+func (l *Logger) With(kv ...any) *Logger {
+	child := *l
+	child.fields = make([]Field, len(l.fields), len(l.fields)+(len(kv)+1)/2)
+	copy(child.fields, l.fields)
+	for i := 0; i < len(kv); i += 2 {
+		field := Field{Key: fmt.Sprint(kv[i])}
+		if i+1 < len(kv) {
+			field.Value = kv[i+1]
+		}
+		child.fields = append(child.fields, field)
+	}
+	return &child
+}
+
+// resolveFields 将 LazyValue 求值并经过脱敏 Hook 后追加到 dst，敏感字段不求值
+func (l *Logger) resolveFields(dst []Field) []Field {
+	for _, field := range l.fields {
+		if l.redactor != nil && l.redactor.IsSecretField(field.Key) {
+			dst = append(dst, Field{Key: field.Key})
+			continue
+		}
+		if lazy, ok := field.Value.(LazyValue); ok {
+			field.Value = lazy()
+		}
+		if l.redactor != nil && l.redactor.Hook != nil {
+			field.Value = l.redactor.Hook(field.Value)
+		}
+		dst = append(dst, field)
+	}
+	return dst
+}
+
+// appendFields 追加 resolveFields 处理后的字段
+func (l *Logger) appendFields(b []byte, fields []Field) []byte {
+	for _, field := range fields {
+		b = append(b, 0x20)
+		if l.enableColor {
+			b = append(b, colorize(field.Key, l.theme.FieldKey)...)
+		} else {
+			b = append(b, field.Key...)
+		}
+		b = append(b, '=')
+		if l.redactor != nil && l.redactor.IsSecretField(field.Key) {
+			b = append(b, REDACT_MASK...)
+			continue
+		}
+		b = l.appendFieldValue(b, field.Value)
+	}
+	return b
+}
+
+func (l *Logger) appendFieldValue(b []byte, value any) []byte {
+	if !l.enableColor && l.redactor == nil {
+		switch v := value.(type) {
+		case int:
+			return strconv.AppendInt(b, int64(v), 10)
+		case int64:
+			return strconv.AppendInt(b, v, 10)
+		case uint64:
+			return strconv.AppendUint(b, v, 10)
+		case float64:
+			return strconv.AppendFloat(b, v, 'g', -1, 64)
+		case bool:
+			return strconv.AppendBool(b, v)
+		}
+	}
+	var str string
+	switch v := value.(type) {
+	case nil:
+		str = "nil"
+	case string:
+		str = StripANSIString(v)
+	default:
+		str = StripANSIString(fmt.Sprint(value))
+	}
+	if l.redactor != nil {
+		str = l.redactor.RedactString(str)
+	}
+	str = truncateString(str, l.maxFieldBytes)
+	if str == "" || strings.ContainsAny(str, " \"=\t\r\n") {
+		str = strconv.Quote(str)
+	}
+	if l.enableColor {
+		return l.appendColorValue(b, str, l.theme.kind(value))
+	}
+	return append(b, str...)
+}
+
+// recordFields 将 resolveFields 处理后的字段按照与文本输出相同的规则(去除颜色、脱敏、截断)原地转换，供 RecordHandler 使用
+//
+// 字符串及 error 转为处理后的字符串，数字等保持原值，其他类型只在脱敏改变其文本时替换为字符串
+func (l *Logger) recordFields(fields []Field) {
+	for i := range fields {
+		if l.redactor != nil && l.redactor.IsSecretField(fields[i].Key) {
+			fields[i].Value = REDACT_MASK
+			continue
+		}
+		value := fields[i].Value
+		switch v := value.(type) {
+		case nil, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, Redacted:
+		case string:
+			if str := l.sanitizeField(v); str != v {
+				value = str
+			}
+		case error:
+			value = l.sanitizeField(v.Error())
+		default:
+			if l.redactor != nil {
+				str := fmt.Sprint(v)
+				if redacted := l.redactor.RedactString(str); redacted != str {
+					value = l.sanitizeField(redacted)
+				}
+			}
+		}
+		fields[i].Value = value
+	}
+}
+
+func (l *Logger) sanitizeField(str string) string {
+	str = StripANSIString(str)
+	if l.redactor != nil {
+		str = l.redactor.RedactString(str)
+	}
+	return truncateString(str, l.maxFieldBytes)
+}
@@ -0,0 +1,303 @@
+package log
+
+import (
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// 异步 handler 默认最多积压的日志条数
+const defaultAsyncPending = 64 * 1024
+
+// 单次批量写入下游的最大字节数
+const asyncBatchSize = 64 * 1024
+
+type asyncNode struct {
+	next atomic.Pointer[asyncNode]
+	data []byte
+	// 下游为 RecordHandler 时保存复制的 Record，Line 即 data
+	hasRecord bool
+	record    Record
+	message   []byte
+	timeText  []byte
+	fields    []Field
+	chain     []string
+}
+
+// asyncHandler 多生产者单消费者(MPSC)的异步 handler
+//
+// 生产者将日志复制到节点后，通过一次原子交换追加到无锁链表(Vyukov MPSC 队列)，不争用锁；
+// 单个写协程按顺序取出并合并为批量写入下游 handler。
+//
+// 顺序保证: 队列顺序即原子交换的顺序，是全局线性一致的顺序；
+// 同一协程的日志按调用顺序写入，不同协程的日志按各自 Write 完成交换的先后写入。
+//
+// 积压超过 maxPending 条时，生产者阻塞等待写协程消费(不丢弃日志)。
+//
+// 下游为 RecordHandler(如 NewRouterHandler)时，Handle 复制 Record 并按顺序交给下游的 Handle；
+// 只写入 Line 的下游(文件、终端)仍合并为批量写入。
+type asyncHandler struct {
+	handler    IHandler
+	record     RecordHandler
+	head       atomic.Pointer[asyncNode] // 生产者交换
+	tail       *asyncNode                // 仅写协程访问
+	stub       asyncNode
+	pending    atomic.Int64
+	maxPending int64
+	nodes      sync.Pool
+	batch      []byte
+
+	// 积压过多时生产者在此等待
+	spaceLock sync.Mutex
+	space     *sync.Cond
+	waiting   atomic.Int32
+
+	wake     chan struct{}
+	flushReq chan chan struct{}
+	done     chan struct{}
+	stopped  chan struct{}
+	closed   atomic.Bool
+}
+
+func (a *asyncHandler) Write(b []byte) (n int, err error) {
+	if a.closed.Load() {
+		return 0, os.ErrClosed
+	}
+	node := a.nodes.Get().(*asyncNode)
+	node.data = append(node.data[:0], b...)
+	a.enqueue(node)
+	return len(b), nil
+}
+
+// Handle 复制 Record 后交给写协程，下游不是 RecordHandler 时只写入 Line
+func (a *asyncHandler) Handle(r Record) error {
+	if a.record == nil {
+		_, err := a.Write(r.Line)
+		return err
+	}
+	if a.closed.Load() {
+		return os.ErrClosed
+	}
+	node := a.nodes.Get().(*asyncNode)
+	node.data = append(node.data[:0], r.Line...)
+	node.message = append(node.message[:0], r.Message...)
+	node.timeText = append(node.timeText[:0], r.TimeText...)
+	node.fields = append(node.fields[:0], r.Fields...)
+	node.chain = append(node.chain[:0], r.ErrorChain...)
+	node.hasRecord = true
+	node.record = r
+	node.record.Line = node.data
+	node.record.Message = node.message
+	node.record.TimeText = node.timeText
+	node.record.Fields = node.fields
+	node.record.ErrorChain = node.chain
+	a.enqueue(node)
+	return nil
+}
+
+func (a *asyncHandler) Enabled(level int) bool {
+	return a.record == nil || a.record.Enabled(level)
+}
+
+func (a *asyncHandler) enqueue(node *asyncNode) {
+	a.push(node)
+	if a.pending.Add(1) > a.maxPending {
+		a.waitSpace()
+	}
+	a.signal()
+}
+
+// waitSpace 积压过多时阻塞等待写协程消费
+func (a *asyncHandler) waitSpace() {
+	a.spaceLock.Lock()
+	defer a.spaceLock.Unlock()
+	a.waiting.Add(1)
+	defer a.waiting.Add(-1)
+	for a.pending.Load() > a.maxPending && !a.closed.Load() {
+		a.signal()
+		a.space.Wait()
+	}
+}
+
+func (a *asyncHandler) notifySpace() {
+	if a.waiting.Load() == 0 {
+		return
+	}
+	a.spaceLock.Lock()
+	a.space.Broadcast()
+	a.spaceLock.Unlock()
+}
+
+// Flush 等待已写入的日志全部交给下游，并刷新下游的缓冲区
+func (a *asyncHandler) Flush() (err error) {
+	if a.closed.Load() {
+		return nil
+	}
+	ack := make(chan struct{})
+	select {
+	case a.flushReq <- ack:
+		<-ack
+	case <-a.stopped:
+	}
+	return nil
+}
+
+func (a *asyncHandler) Close() (err error) {
+	if a.closed.Swap(true) {
+		return nil
+	}
+	close(a.done)
+	<-a.stopped
+	a.notifySpace()
+	return a.handler.Close()
+}
+
+func (a *asyncHandler) Unwrap() IHandler {
+	return a.handler
+}
+
+func (a *asyncHandler) signal() {
+	select {
+	case a.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (a *asyncHandler) push(node *asyncNode) {
+	node.next.Store(nil)
+	prev := a.head.Swap(node)
+	prev.next.Store(node)
+}
+
+// pop 取出队首节点，队列为空或生产者尚未完成链接时返回 nil
+func (a *asyncHandler) pop() *asyncNode {
+	tail := a.tail
+	next := tail.next.Load()
+	if tail == &a.stub {
+		if next == nil {
+			return nil
+		}
+		a.tail = next
+		tail = next
+		next = next.next.Load()
+	}
+	if next != nil {
+		a.tail = next
+		return tail
+	}
+	if tail != a.head.Load() {
+		return nil
+	}
+	a.push(&a.stub)
+	if next = tail.next.Load(); next != nil {
+		a.tail = next
+		return tail
+	}
+	return nil
+}
+
+func (a *asyncHandler) run() {
+	defer close(a.stopped)
+	for {
+		a.drain()
+		select {
+		case <-a.wake:
+		case ack := <-a.flushReq:
+			a.drain()
+			if f, ok := a.handler.(IFlusher); ok {
+				_ = f.Flush()
+			}
+			close(ack)
+		case <-a.done:
+			a.drain()
+			if f, ok := a.handler.(IFlusher); ok {
+				_ = f.Flush()
+			}
+			return
+		}
+	}
+}
+
+// drain 取出所有节点，合并后批量写入下游，Record 先写入之前的批量再交给下游的 Handle
+func (a *asyncHandler) drain() {
+	for {
+		node := a.pop()
+		if node == nil {
+			break
+		}
+		if node.hasRecord {
+			a.writeBatch()
+			_ = a.record.Handle(node.record)
+		} else {
+			if len(a.batch)+len(node.data) > asyncBatchSize && len(a.batch) > 0 {
+				a.writeBatch()
+			}
+			a.batch = append(a.batch, node.data...)
+		}
+		a.release(node)
+		a.pending.Add(-1)
+	}
+	a.writeBatch()
+	a.notifySpace()
+}
+
+// release 清除节点对字段值的引用后放回池中
+func (a *asyncHandler) release(node *asyncNode) {
+	if cap(node.data) > maxPoolBufferSize || cap(node.message) > maxPoolBufferSize {
+		return
+	}
+	node.hasRecord = false
+	node.record = Record{}
+	for i := range node.fields {
+		node.fields[i] = Field{}
+	}
+	node.fields = node.fields[:0]
+	for i := range node.chain {
+		node.chain[i] = ""
+	}
+	node.chain = node.chain[:0]
+	a.nodes.Put(node)
+}
+
+func (a *asyncHandler) writeBatch() {
+	if len(a.batch) == 0 {
+		return
+	}
+	_, _ = a.handler.Write(a.batch)
+	a.batch = a.batch[:0]
+}
+
+// NewAsyncHandler 包装 handler 为无锁的异步 handler，maxPending < 1 时默认 64K 条
+//
+// handler 实现 RecordHandler 时，返回的 handler 也实现 RecordHandler，按顺序转发复制的 Record
+//
+// 退出前需调用 Close 或 Flush，否则尚未写入的日志会丢失
+func NewAsyncHandler(handler IHandler, maxPending int) IHandler {
+	if maxPending < 1 {
+		maxPending = defaultAsyncPending
+	}
+	a := &asyncHandler{
+		handler:    handler,
+		maxPending: int64(maxPending),
+		batch:      make([]byte, 0, asyncBatchSize),
+		nodes: sync.Pool{
+			New: func() any {
+				return &asyncNode{data: make([]byte, 0, 256)}
+			},
+		},
+		wake:     make(chan struct{}, 1),
+		flushReq: make(chan chan struct{}),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	if record, ok := handler.(RecordHandler); ok {
+		if _, ok = handler.(lineHandler); !ok {
+			a.record = record
+		}
+	}
+	a.space = sync.NewCond(&a.spaceLock)
+	a.head.Store(&a.stub)
+	a.tail = &a.stub
+	go a.run()
+	return a
+}
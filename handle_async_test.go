@@ -0,0 +1,179 @@
+package log
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingHandler 在 release 关闭前阻塞 Write，started 在首次 Write 时关闭
+type blockingHandler struct {
+	bufferHandler
+	once    sync.Once
+	started chan struct{}
+	release chan struct{}
+}
+
+func newBlockingHandler() *blockingHandler {
+	return &blockingHandler{started: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (h *blockingHandler) Write(b []byte) (n int, err error) {
+	h.once.Do(func() { close(h.started) })
+	<-h.release
+	return h.bufferHandler.Write(b)
+}
+
+func TestAsyncOrderPerGoroutine(t *testing.T) {
+	const goroutines, count = 8, 1000
+	h := &bufferHandler{}
+	a := NewAsyncHandler(h, 16)
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < count; i++ {
+				_, _ = a.Write([]byte(fmt.Sprintf("%d:%d\n", g, i)))
+			}
+		}(g)
+	}
+	wg.Wait()
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	next := make([]int, goroutines)
+	lines := strings.Split(strings.TrimSuffix(h.String(), "\n"), "\n")
+	if len(lines) != goroutines*count {
+		t.Fatalf("got %d lines, want %d", len(lines), goroutines*count)
+	}
+	for _, line := range lines {
+		g, i, _ := strings.Cut(line, ":")
+		gi, _ := strconv.Atoi(g)
+		ii, _ := strconv.Atoi(i)
+		if ii != next[gi] {
+			t.Fatalf("goroutine %d: got %d, want %d", gi, ii, next[gi])
+		}
+		next[gi]++
+	}
+}
+
+func TestAsyncFlush(t *testing.T) {
+	h := &bufferHandler{}
+	a := NewAsyncHandler(h, 0)
+	defer a.Close()
+	for i := 0; i < 100; i++ {
+		_, _ = a.Write([]byte("line\n"))
+	}
+	if err := a.(IFlusher).Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(h.String(), "line\n"); got != 100 {
+		t.Fatalf("got %d lines after Flush, want 100", got)
+	}
+}
+
+func TestAsyncBackpressure(t *testing.T) {
+	const maxPending = 4
+	h := newBlockingHandler()
+	a := NewAsyncHandler(h, maxPending)
+	// 写协程取出第一条后阻塞在下游
+	_, _ = a.Write([]byte("x\n"))
+	<-h.started
+
+	var written sync.WaitGroup
+	returned := make(chan struct{}, 100)
+	written.Add(1)
+	go func() {
+		defer written.Done()
+		for i := 0; i < 20; i++ {
+			_, _ = a.Write([]byte("x\n"))
+			returned <- struct{}{}
+		}
+	}()
+	time.Sleep(50 * time.Millisecond)
+	// 积压超过 maxPending 的那次 Write 阻塞
+	if n := len(returned); n != maxPending {
+		t.Fatalf("%d writes returned while the downstream is blocked, want %d", n, maxPending)
+	}
+	if pending := a.(*asyncHandler).pending.Load(); pending != maxPending+1 {
+		t.Fatalf("got %d pending records, want %d", pending, maxPending+1)
+	}
+	close(h.release)
+	written.Wait()
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(h.String(), "x\n"); got != 21 {
+		t.Fatalf("got %d lines, want 21", got)
+	}
+}
+
+func TestAsyncCloseWithBlockedProducers(t *testing.T) {
+	h := newBlockingHandler()
+	a := NewAsyncHandler(h, 1)
+
+	var producers sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		producers.Add(1)
+		go func() {
+			defer producers.Done()
+			for i := 0; i < 10; i++ {
+				_, _ = a.Write([]byte("x\n"))
+			}
+		}()
+	}
+	<-h.started
+	closed := make(chan error)
+	go func() { closed <- a.Close() }()
+	time.Sleep(20 * time.Millisecond)
+	close(h.release)
+
+	done := make(chan struct{})
+	go func() {
+		producers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("producers still blocked after Close")
+	}
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close did not return")
+	}
+	if _, err := a.Write([]byte("late\n")); err == nil {
+		t.Fatal("Write after Close should fail")
+	}
+}
+
+func TestAsyncForwardsRecords(t *testing.T) {
+	errors, all := &bufferHandler{}, &bufferHandler{}
+	router := NewRouterHandler(
+		Route{MinLevel: LV_ERROR, MaxLevel: LV_FATAL, Handler: errors},
+		Route{MinLevel: LV_DEBUG, MaxLevel: LV_FATAL, Handler: all},
+	)
+	logger := New(router, WithColorMode(FLAG_COLOR_NEVER), WithLineFormat("{msg}{fields}"), WithAsync(0))
+	if _, ok := logger.handler.(RecordHandler); !ok {
+		t.Fatal("async handler around a RecordHandler should be a RecordHandler")
+	}
+	logger.With("n", 1).Info("started")
+	logger.Error("failed")
+	_ = logger.Close()
+
+	if got, want := errors.String(), "failed\n"; got != want {
+		t.Fatalf("error route got %q, want %q", got, want)
+	}
+	if got, want := all.String(), "started n=1\nfailed\n"; got != want {
+		t.Fatalf("all route got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,91 @@
+package log
+
+import (
+	"bufio"
+	"os"
+	"time"
+)
+
+// 默认的文件缓冲区大小
+const defaultFileBufferSize = 256 * 1024
+
+// IFlusher 支持刷新缓冲区的 handler
+type IFlusher interface {
+	Flush() error
+}
+
+// bufferable 支持开启缓冲写入的 handler，见 WithFileBuffer
+type bufferable interface {
+	setBuffer(size int, flushInterval time.Duration)
+}
+
+// fileBuffer 文件 handler 可选的缓冲写入，调用方需持有 handler 的锁
+type fileBuffer struct {
+	w    *bufio.Writer
+	stop chan struct{}
+}
+
+func (b *fileBuffer) write(fd *os.File, p []byte) (int, error) {
+	if b.w == nil {
+		return fd.Write(p)
+	}
+	return b.w.Write(p)
+}
+
+func (b *fileBuffer) flush() error {
+	if b.w == nil {
+		return nil
+	}
+	return b.w.Flush()
+}
+
+// reset 轮转后切换到新文件
+func (b *fileBuffer) reset(fd *os.File) {
+	if b.w != nil {
+		b.w.Reset(fd)
+	}
+}
+
+// enable 开启缓冲，flushInterval > 0 时定时调用 flush(需自行加锁)
+func (b *fileBuffer) enable(fd *os.File, size int, flushInterval time.Duration, flush func() error) {
+	if size < 1 {
+		size = defaultFileBufferSize
+	}
+	if b.w != nil {
+		_ = b.w.Flush()
+	}
+	b.w = bufio.NewWriterSize(fd, size)
+	b.stopFlusher()
+	if flushInterval > 0 {
+		b.stop = make(chan struct{})
+		go runFlusher(flushInterval, b.stop, flush)
+	}
+}
+
+// close 刷新并关闭缓冲
+func (b *fileBuffer) close() error {
+	b.stopFlusher()
+	err := b.flush()
+	b.w = nil
+	return err
+}
+
+func (b *fileBuffer) stopFlusher() {
+	if b.stop != nil {
+		close(b.stop)
+		b.stop = nil
+	}
+}
+
+func runFlusher(interval time.Duration, stop chan struct{}, flush func() error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			_ = flush()
+		case <-stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package log
+
+import (
+	"bytes"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// dedupHandler 合并连续重复的日志
+//
+// 以级别、调用位置及消息内容比较(只有 Write 时为去除颜色的整行)，连续相同的日志只写入第一条，
+// 出现不同的日志、超时或 Close 时写入 "last message repeated N times"
+type dedupHandler struct {
+	handler IHandler
+
+	lock     sync.Mutex
+	hasLast  bool
+	repeated int
+	// 最近一条日志
+	level   int
+	caller  Caller
+	name    string
+	message []byte
+	scratch []byte
+	summary []byte
+
+	stop   chan struct{}
+	closed atomic.Bool
+}
+
+func (d *dedupHandler) Handle(r Record) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.repeat(r.Level, r.Caller, r.Message) {
+		return nil
+	}
+	d.name = r.Name
+	return writeRecord(d.handler, r)
+}
+
+func (d *dedupHandler) Enabled(level int) bool {
+	if rh, ok := d.handler.(RecordHandler); ok {
+		return rh.Enabled(level)
+	}
+	return true
+}
+
+// Write 没有级别等信息时以去除颜色的整行比较
+func (d *dedupHandler) Write(b []byte) (n int, err error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.scratch = StripANSI(append(d.scratch[:0], b...))
+	if d.repeat(LV_PRINT, Caller{}, trimNewline(d.scratch, 0)) {
+		return len(b), nil
+	}
+	d.name = ""
+	return d.handler.Write(b)
+}
+
+// repeat 与上一条日志相同时计数，否则写入之前的重复次数并记录当前日志，调用方需持有锁
+func (d *dedupHandler) repeat(level int, caller Caller, message []byte) bool {
+	if d.hasLast && level == d.level && caller == d.caller && bytes.Equal(message, d.message) {
+		d.repeated++
+		return true
+	}
+	_ = d.writeRepeated()
+	d.hasLast = true
+	d.level, d.caller = level, caller
+	d.message = append(d.message[:0], message...)
+	return false
+}
+
+// writeRepeated 写入重复次数并重新计数，调用方需持有锁
+func (d *dedupHandler) writeRepeated() error {
+	if d.repeated == 0 {
+		return nil
+	}
+	d.summary = append(d.summary[:0], "last message repeated "...)
+	d.summary = strconv.AppendInt(d.summary, int64(d.repeated), 10)
+	d.summary = append(d.summary, " times\n"...)
+	d.repeated = 0
+	return writeRecord(d.handler, Record{
+		Level:   d.level,
+		Time:    time.Now(),
+		Caller:  d.caller,
+		Name:    d.name,
+		Message: trimNewline(d.summary, 0),
+		Line:    d.summary,
+	})
+}
+
+// timeout 定时写入重复次数，之后相同的日志重新计数
+func (d *dedupHandler) timeout() error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.writeRepeated()
+}
+
+// Flush 刷新下游的缓冲区，重复次数只在出现不同的日志、超时或 Close 时写入
+func (d *dedupHandler) Flush() (err error) {
+	if f, ok := d.handler.(IFlusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+func (d *dedupHandler) Close() (err error) {
+	if d.closed.Swap(true) {
+		return nil
+	}
+	close(d.stop)
+	_ = d.timeout()
+	return d.handler.Close()
+}
+
+func (d *dedupHandler) Unwrap() IHandler {
+	return d.handler
+}
+
+// NewDedupHandler 包装 handler，合并连续重复的日志，每隔 timeout(<= 0 时 30s)写入尚未写入的重复次数
+func NewDedupHandler(handler IHandler, timeout time.Duration) IHandler {
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	d := &dedupHandler{
+		handler: handler,
+		stop:    make(chan struct{}),
+	}
+	go runFlusher(timeout, d.stop, d.timeout)
+	return d
+}
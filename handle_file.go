@@ -15,27 +15,54 @@ type fileHandler struct {
 	fileName string
 	maxSize  int64
 	curSize  atomic.Int64
+	buffer   fileBuffer
 }
 
 func (f *fileHandler) Write(b []byte) (n int, err error) {
 	f.lock.Lock()
 	defer f.lock.Unlock()
 	f.check()
-	n, err = f.fd.Write(b)
+	n, err = f.buffer.write(f.fd, b)
 	f.curSize.Add(int64(n))
 	return
 }
 
+// Handle 写入 r.Line
+func (f *fileHandler) Handle(r Record) error {
+	_, err := f.Write(r.Line)
+	return err
+}
+
+func (f *fileHandler) Enabled(level int) bool {
+	return true
+}
+
+func (f *fileHandler) lineOnly() {}
+
 func (f *fileHandler) Close() (err error) {
 	f.lock.Lock()
 	defer f.lock.Unlock()
+	_ = f.buffer.close()
 	return f.fd.Close()
 }
 
+func (f *fileHandler) Flush() (err error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.buffer.flush()
+}
+
+func (f *fileHandler) setBuffer(size int, flushInterval time.Duration) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.buffer.enable(f.fd, size, flushInterval, f.Flush)
+}
+
 func (f *fileHandler) check() {
 	if f.maxSize > f.curSize.Load() {
 		return
 	}
+	_ = f.buffer.flush()
 	stat, err := f.fd.Stat()
 	if err != nil {
 		return
@@ -50,6 +77,7 @@ func (f *fileHandler) check() {
 	_ = f.fd.Close()
 	_ = os.Rename(f.fileName, bakFileName)
 	f.fd, _ = os.OpenFile(f.fileName, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0666)
+	f.buffer.reset(f.fd)
 	f.curSize.Store(0)
 	fi, err := f.fd.Stat()
 	if err != nil {
@@ -16,22 +16,48 @@ type fileRotateHandler struct {
 	maxAgeHours    int       // 最大存储小时
 	hoursInterval  int       // 每几小时
 	lastRotateTime time.Time // 上次轮转时间
+	buffer         fileBuffer
 }
 
 func (f *fileRotateHandler) Write(b []byte) (n int, err error) {
 	f.lock.Lock()
 	defer f.lock.Unlock()
 	f.check()
-	n, err = f.fd.Write(b)
+	n, err = f.buffer.write(f.fd, b)
 	return
 }
 
+// Handle 写入 r.Line
+func (f *fileRotateHandler) Handle(r Record) error {
+	_, err := f.Write(r.Line)
+	return err
+}
+
+func (f *fileRotateHandler) Enabled(level int) bool {
+	return true
+}
+
+func (f *fileRotateHandler) lineOnly() {}
+
 func (f *fileRotateHandler) Close() (err error) {
 	f.lock.Lock()
 	defer f.lock.Unlock()
+	_ = f.buffer.close()
 	return f.fd.Close()
 }
 
+func (f *fileRotateHandler) Flush() (err error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.buffer.flush()
+}
+
+func (f *fileRotateHandler) setBuffer(size int, flushInterval time.Duration) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.buffer.enable(f.fd, size, flushInterval, f.Flush)
+}
+
 func (f *fileRotateHandler) check() {
 	now := time.Now()
 	if f.lastRotateTime.IsZero() {
@@ -45,9 +71,11 @@ func (f *fileRotateHandler) check() {
 	}
 	dir, fileName := GetDirAndFileName(f.file, "log.log")
 	bakFileName := fmt.Sprintf("%sbak_%s_%s", dir, f.lastRotateTime.Format("2006010215"), fileName)
+	_ = f.buffer.flush()
 	_ = f.fd.Close()
 	_ = os.Rename(f.file, bakFileName)
 	f.fd, _ = os.OpenFile(f.file, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0666)
+	f.buffer.reset(f.fd)
 	f.lastRotateTime = nextRotateTime
 
 	go f.cleanOldFiles(dir, now)
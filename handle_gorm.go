@@ -60,31 +60,42 @@ func (g *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (stri
 	switch {
 	case err != nil && LV_ERROR >= g._level && (!errors.Is(err, gorm.ErrRecordNotFound) || !g.ignoreRecordNotFoundError):
 		sql, rows := fc()
+		sql = g.base.prepareSQL(sql)
 		if g.base.enableColor {
-			sql = ColorWrap(sql, COLOR_FG_CYAN)
+			sql = colorize(sql, g.base.theme.SQL)
 		}
 		file, line := _caller_file_line()
-		file = ShortFileName(file)
+		file = FormatFileName(file, g.base.callerPath)
 		g.base.logf_gorm(LV_ERROR, "[%s:%d rows:%d %.3fms] %s err: %v", file, line, rows, float64(elapsed.Nanoseconds())/1e6, sql, err)
 	case elapsed >= g.slowThreshold && LV_WARN >= g._level && g.slowThreshold > 0:
 		sql, rows := fc()
+		sql = g.base.prepareSQL(sql)
 		if g.base.enableColor {
-			sql = ColorWrap(sql, COLOR_FG_CYAN)
+			sql = colorize(sql, g.base.theme.SQL)
 		}
 		file, line := _caller_file_line()
-		file = ShortFileName(file)
+		file = FormatFileName(file, g.base.callerPath)
 		g.base.logf_gorm(LV_WARN, "[%s:%d rows:%d %.3fms] %s", file, line, rows, float64(elapsed.Nanoseconds())/1e6, sql)
 	case LV_INFO >= g._level:
 		sql, rows := fc()
+		sql = g.base.prepareSQL(sql)
 		if g.base.enableColor {
-			sql = ColorWrap(sql, COLOR_FG_CYAN)
+			sql = colorize(sql, g.base.theme.SQL)
 		}
 		file, line := _caller_file_line()
-		file = ShortFileName(file)
+		file = FormatFileName(file, g.base.callerPath)
 		g.base.logf_gorm(LV_INFO, "[%s:%d rows:%d %.3fms] %s", file, line, rows, float64(elapsed.Nanoseconds())/1e6, sql)
 	}
 }
 
+// prepareSQL 脱敏并截断 SQL
+func (l *Logger) prepareSQL(sql string) string {
+	if l.redactor != nil {
+		sql = l.redactor.RedactString(sql)
+	}
+	return truncateString(sql, l.maxMessageBytes)
+}
+
 func _caller_file_line() (string, int) {
 	var pcs [32]uintptr
 	n := runtime.Callers(3, pcs[:])
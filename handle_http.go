@@ -0,0 +1,540 @@
+package log
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+)
+
+// 日志级别在 JSON 及 Loki 标签中的名称
+var httpLevelNames = map[int]string{
+	LV_DEBUG: "debug",
+	LV_PRINT: "print",
+	LV_INFO:  "info",
+	LV_WARN:  "warn",
+	LV_ERROR: "error",
+	LV_PANIC: "panic",
+	LV_FATAL: "fatal",
+}
+
+// HTTPConfig HTTP 批量发送 handler 的配置
+type HTTPConfig struct {
+	// eg: http://loki:3100/loki/api/v1/push、http://es:9200/_bulk
+	URL    string
+	Format FLAG_HTTP_FORMAT
+	// 附加的请求头，eg: Authorization
+	Headers map[string]string
+	// 默认超时 10s
+	Client *http.Client
+	// 达到任一条件时发送: 条数(默认 1000)、字节数(默认 1M)、时间间隔(默认 1s)
+	BatchSize     int
+	BatchBytes    int
+	BatchInterval time.Duration
+	// 等待发送的最大条数(默认 10000)，队列满时丢弃新的日志
+	QueueSize int
+	// 网络错误、429 及 5xx 时的最大重试次数(默认 3)，退避时间默认 500ms ~ 10s；小于 0 时不重试
+	MaxRetries int
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// 使用 gzip 压缩请求体
+	Gzip bool
+	// Loki 的静态标签，另外会附加 level 及 logger(Logger 的名称)
+	Labels map[string]string
+	// Elasticsearch 的索引，为空时使用 URL 中的索引
+	Index string
+}
+
+// HTTPStats HTTP handler 的计数
+type HTTPStats struct {
+	RecordsSent    uint64
+	RecordsDropped uint64
+	Requests       uint64
+	Retries        uint64
+}
+
+// IHTTPStats HTTP handler 的计数
+type IHTTPStats interface {
+	Stats() HTTPStats
+}
+
+// httpEntry 编码后的一条日志: JSON 及 Elasticsearch 为文档，Loki 为 ["<ns>","<line>"]
+type httpEntry struct {
+	level int
+	name  string
+	data  []byte
+}
+
+// httpHandler 批量发送日志到 HTTP 接口
+//
+// Handle 在调用方协程中编码并放入有界队列，由单个协程按条数、字节数或时间间隔合并发送；
+// 发送失败(重试后)及队列已满时丢弃，计入 RecordsDropped
+type httpHandler struct {
+	config HTTPConfig
+
+	// Handle 与 Close 互斥，避免关闭后仍放入队列
+	closeLock sync.RWMutex
+	closed    bool
+	queue     chan httpEntry
+	flushReq  chan chan struct{}
+	done      chan struct{}
+	stopped   chan struct{}
+
+	// 仅发送协程访问
+	batch      []httpEntry
+	batchBytes int
+	body       bytes.Buffer
+	gzip       *gzip.Writer
+
+	recordsSent    atomic.Uint64
+	recordsDropped atomic.Uint64
+	requests       atomic.Uint64
+	retries        atomic.Uint64
+}
+
+func (h *httpHandler) Handle(r Record) error {
+	entry := httpEntry{level: r.Level, name: r.Name, data: h.encode(r)}
+	h.closeLock.RLock()
+	defer h.closeLock.RUnlock()
+	if h.closed {
+		return os.ErrClosed
+	}
+	select {
+	case h.queue <- entry:
+	default:
+		h.recordsDropped.Add(1)
+	}
+	return nil
+}
+
+func (h *httpHandler) Enabled(level int) bool {
+	return true
+}
+
+// Write 没有级别信息时以 LV_PRINT 发送
+func (h *httpHandler) Write(b []byte) (n int, err error) {
+	msg := StripANSI(trimNewline(append([]byte(nil), b...), 0))
+	return len(b), h.Handle(Record{Level: LV_PRINT, Time: time.Now(), Message: msg, Line: b})
+}
+
+func (h *httpHandler) Stats() HTTPStats {
+	return HTTPStats{
+		RecordsSent:    h.recordsSent.Load(),
+		RecordsDropped: h.recordsDropped.Load(),
+		Requests:       h.requests.Load(),
+		Retries:        h.retries.Load(),
+	}
+}
+
+// Flush 发送队列中的日志并等待完成
+func (h *httpHandler) Flush() (err error) {
+	ack := make(chan struct{})
+	select {
+	case h.flushReq <- ack:
+		<-ack
+	case <-h.stopped:
+	}
+	return nil
+}
+
+// Close 发送队列中的日志后退出
+func (h *httpHandler) Close() (err error) {
+	h.closeLock.Lock()
+	if h.closed {
+		h.closeLock.Unlock()
+		return nil
+	}
+	h.closed = true
+	h.closeLock.Unlock()
+	close(h.done)
+	<-h.stopped
+	return nil
+}
+
+func (h *httpHandler) encode(r Record) []byte {
+	if h.config.Format == FLAG_HTTP_LOKI {
+		b := append(make([]byte, 0, len(r.Line)+32), `["`...)
+		b = strconv.AppendInt(b, r.Time.UnixNano(), 10)
+		b = append(b, `",`...)
+		b = appendJSONString(b, string(StripANSI(trimNewline(append([]byte(nil), r.Line...), 0))))
+		return append(b, ']')
+	}
+	b := make([]byte, 0, len(r.Message)+128)
+	if h.config.Format == FLAG_HTTP_ELASTIC || len(r.TimeText) == 0 {
+		// Elasticsearch 的 @timestamp 需要可解析的日期
+		b = append(b, ifs(h.config.Format == FLAG_HTTP_ELASTIC, `{"@timestamp":`, `{"time":`)...)
+		b = appendJSONString(b, r.Time.Format(time.RFC3339Nano))
+	} else {
+		b = append(b, `{"time":`...)
+		b = appendJSONString(b, string(r.TimeText))
+	}
+	b = append(b, `,"level":`...)
+	b = appendJSONString(b, httpLevelNames[r.Level])
+	if r.Name != "" {
+		b = append(b, `,"logger":`...)
+		b = appendJSONString(b, r.Name)
+	}
+	if r.Caller.File != "" {
+		b = append(b, `,"caller":`...)
+		b = appendJSONString(b, r.Caller.File+":"+strconv.Itoa(r.Caller.Line))
+		b = append(b, `,"func":`...)
+		b = appendJSONString(b, r.Caller.Func)
+	}
+	b = append(b, `,"message":`...)
+	b = appendJSONString(b, string(r.Message))
+	if len(r.Fields) > 0 {
+		b = append(b, `,"fields":{`...)
+		for i, field := range r.Fields {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = appendJSONString(b, field.Key)
+			b = append(b, ':')
+			b = appendJSONValue(b, field.Value)
+		}
+		b = append(b, '}')
+	}
+	if len(r.ErrorChain) > 0 {
+		b = append(b, `,"error.chain":[`...)
+		for i, text := range r.ErrorChain {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = appendJSONString(b, text)
+		}
+		b = append(b, ']')
+	}
+	return append(b, '}')
+}
+
+func (h *httpHandler) run() {
+	defer close(h.stopped)
+	ticker := time.NewTicker(h.config.BatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case entry := <-h.queue:
+			h.add(entry)
+		case <-ticker.C:
+			h.flush()
+		case ack := <-h.flushReq:
+			h.drain()
+			h.flush()
+			close(ack)
+		case <-h.done:
+			h.drain()
+			h.flush()
+			return
+		}
+	}
+}
+
+func (h *httpHandler) add(entry httpEntry) {
+	h.batch = append(h.batch, entry)
+	h.batchBytes += len(entry.data)
+	if len(h.batch) >= h.config.BatchSize || h.batchBytes >= h.config.BatchBytes {
+		h.flush()
+	}
+}
+
+// drain 取出队列中已有的日志
+func (h *httpHandler) drain() {
+	for {
+		select {
+		case entry := <-h.queue:
+			h.add(entry)
+		default:
+			return
+		}
+	}
+}
+
+func (h *httpHandler) flush() {
+	if len(h.batch) == 0 {
+		return
+	}
+	if err := h.send(h.encodeBatch()); err != nil {
+		h.recordsDropped.Add(uint64(len(h.batch)))
+	} else {
+		h.recordsSent.Add(uint64(len(h.batch)))
+	}
+	for i := range h.batch {
+		h.batch[i] = httpEntry{}
+	}
+	h.batch = h.batch[:0]
+	h.batchBytes = 0
+}
+
+func (h *httpHandler) encodeBatch() []byte {
+	var b []byte
+	switch h.config.Format {
+	case FLAG_HTTP_LOKI:
+		b = h.encodeLoki(b)
+	case FLAG_HTTP_ELASTIC:
+		action := []byte(`{"create":{}}` + "\n")
+		if h.config.Index != "" {
+			action = append(appendJSONString([]byte(`{"create":{"_index":`), h.config.Index), "}}\n"...)
+		}
+		for _, entry := range h.batch {
+			b = append(b, action...)
+			b = append(b, entry.data...)
+			b = append(b, 0x0a)
+		}
+	default:
+		b = append(b, '[')
+		for i, entry := range h.batch {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = append(b, entry.data...)
+		}
+		b = append(b, ']')
+	}
+	return b
+}
+
+// encodeLoki 按级别及 Logger 名称分为不同的 stream
+func (h *httpHandler) encodeLoki(b []byte) []byte {
+	type streamKey struct {
+		level int
+		name  string
+	}
+	streams := make(map[streamKey][]httpEntry)
+	var keys []streamKey
+	for _, entry := range h.batch {
+		key := streamKey{entry.level, entry.name}
+		if _, ok := streams[key]; !ok {
+			keys = append(keys, key)
+		}
+		streams[key] = append(streams[key], entry)
+	}
+	labels := make([]string, 0, len(h.config.Labels))
+	for name := range h.config.Labels {
+		labels = append(labels, name)
+	}
+	sort.Strings(labels)
+	b = append(b, `{"streams":[`...)
+	for i, key := range keys {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		b = append(b, `{"stream":{`...)
+		for _, name := range labels {
+			b = appendJSONString(b, name)
+			b = append(b, ':')
+			b = appendJSONString(b, h.config.Labels[name])
+			b = append(b, ',')
+		}
+		b = append(b, `"level":`...)
+		b = appendJSONString(b, httpLevelNames[key.level])
+		if key.name != "" {
+			b = append(b, `,"logger":`...)
+			b = appendJSONString(b, key.name)
+		}
+		b = append(b, `},"values":[`...)
+		for j, entry := range streams[key] {
+			if j > 0 {
+				b = append(b, ',')
+			}
+			b = append(b, entry.data...)
+		}
+		b = append(b, "]}"...)
+	}
+	return append(b, "]}"...)
+}
+
+// send 发送请求体，网络错误、429 及 5xx 时按指数退避重试
+func (h *httpHandler) send(payload []byte) error {
+	if h.config.Gzip {
+		h.body.Reset()
+		h.gzip.Reset(&h.body)
+		_, _ = h.gzip.Write(payload)
+		_ = h.gzip.Close()
+		payload = h.body.Bytes()
+	}
+	backoff := h.config.MinBackoff
+	for attempt := 0; ; attempt++ {
+		err := h.post(payload)
+		if err == nil {
+			return nil
+		}
+		var status httpStatusError
+		if errors.As(err, &status) && status != http.StatusTooManyRequests && status < 500 {
+			return err
+		}
+		if attempt >= h.config.MaxRetries {
+			return err
+		}
+		h.retries.Add(1)
+		time.Sleep(backoff/2 + time.Duration(rand.Int63n(int64(backoff))))
+		if backoff *= 2; backoff > h.config.MaxBackoff {
+			backoff = h.config.MaxBackoff
+		}
+	}
+}
+
+type httpStatusError int
+
+func (e httpStatusError) Error() string {
+	return "http status " + strconv.Itoa(int(e))
+}
+
+func (h *httpHandler) post(payload []byte) error {
+	req, err := http.NewRequest(http.MethodPost, h.config.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	if h.config.Format == FLAG_HTTP_ELASTIC {
+		req.Header.Set("Content-Type", "application/x-ndjson")
+	} else {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if h.config.Gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for key, value := range h.config.Headers {
+		req.Header.Set(key, value)
+	}
+	h.requests.Add(1)
+	resp, err := h.config.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return httpStatusError(resp.StatusCode)
+	}
+	return nil
+}
+
+// appendJSONString 追加 JSON 字符串，无效的 UTF-8 替换为 U+FFFD
+func appendJSONString(b []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	b = append(b, '"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			switch {
+			case c == '"' || c == '\\':
+				b = append(b, '\\', c)
+			case c == '\n':
+				b = append(b, '\\', 'n')
+			case c == '\r':
+				b = append(b, '\\', 'r')
+			case c == '\t':
+				b = append(b, '\\', 't')
+			case c < 0x20:
+				b = append(b, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+			default:
+				b = append(b, c)
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			b = append(b, "\ufffd"...)
+		} else {
+			b = append(b, s[i:i+size]...)
+		}
+		i += size
+	}
+	return append(b, '"')
+}
+
+// appendJSONValue 追加字段值，无法编码为 JSON 的值使用 fmt.Sprint 的结果
+func appendJSONValue(b []byte, value any) []byte {
+	switch v := value.(type) {
+	case nil:
+		return append(b, "null"...)
+	case string:
+		return appendJSONString(b, v)
+	case bool:
+		return strconv.AppendBool(b, v)
+	case int:
+		return strconv.AppendInt(b, int64(v), 10)
+	case int64:
+		return strconv.AppendInt(b, v, 10)
+	case uint64:
+		return strconv.AppendUint(b, v, 10)
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return appendJSONString(b, strconv.FormatFloat(v, 'g', -1, 64))
+		}
+		return strconv.AppendFloat(b, v, 'g', -1, 64)
+	case error:
+		return appendJSONString(b, v.Error())
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return appendJSONString(b, fmt.Sprint(value))
+	}
+	return append(b, data...)
+}
+
+// NewHTTPHandler 创建批量发送日志到 HTTP 接口的 handler，格式见 FLAG_HTTP_JSON、FLAG_HTTP_LOKI、FLAG_HTTP_ELASTIC
+func NewHTTPHandler(config HTTPConfig) (IHandler, error) {
+	if _, err := url.ParseRequestURI(config.URL); err != nil {
+		return nil, err
+	}
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if config.BatchSize < 1 {
+		config.BatchSize = 1000
+	}
+	if config.BatchBytes < 1 {
+		config.BatchBytes = 1024 * 1024
+	}
+	if config.BatchInterval <= 0 {
+		config.BatchInterval = time.Second
+	}
+	if config.QueueSize < 1 {
+		config.QueueSize = 10000
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = 3
+	}
+	if config.MinBackoff <= 0 {
+		config.MinBackoff = 500 * time.Millisecond
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = 10 * time.Second
+	}
+	if config.MaxBackoff < config.MinBackoff {
+		config.MaxBackoff = config.MinBackoff
+	}
+	h := &httpHandler{
+		config:   config,
+		queue:    make(chan httpEntry, config.QueueSize),
+		flushReq: make(chan chan struct{}),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+		gzip:     gzip.NewWriter(io.Discard),
+	}
+	go h.run()
+	return h, nil
+}
+
+// NewHTTPLogger 创建批量发送到 HTTP 接口的 Logger，默认不输出颜色
+func NewHTTPLogger(config HTTPConfig, opts ...Option) (*Logger, error) {
+	handler, err := NewHTTPHandler(config)
+	if err != nil {
+		return nil, err
+	}
+	return New(handler, append([]Option{WithColorMode(FLAG_COLOR_NEVER)}, opts...)...), nil
+}
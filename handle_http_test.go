@@ -0,0 +1,281 @@
+package log
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var httpTestTime = time.Date(2026, 10, 19, 15, 4, 5, 0, time.UTC)
+
+// httpTestServer 记录收到的请求体，status 依次作为响应码，用完后返回 200
+type httpTestServer struct {
+	*httptest.Server
+	lock     sync.Mutex
+	status   []int
+	bodies   []string
+	received chan struct{}
+}
+
+func newHTTPTestServer(status ...int) *httpTestServer {
+	s := &httpTestServer{status: status, received: make(chan struct{}, 100)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.lock.Lock()
+		code := http.StatusOK
+		if len(s.status) > 0 {
+			code, s.status = s.status[0], s.status[1:]
+		}
+		if code == http.StatusOK {
+			s.bodies = append(s.bodies, string(body))
+		}
+		s.lock.Unlock()
+		w.WriteHeader(code)
+		s.received <- struct{}{}
+	}))
+	return s
+}
+
+func (s *httpTestServer) wait(t *testing.T, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-s.received:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("got %d requests, want %d", i, n)
+		}
+	}
+}
+
+func (s *httpTestServer) Bodies() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]string(nil), s.bodies...)
+}
+
+func newTestHTTPHandler(t *testing.T, config HTTPConfig) *httpHandler {
+	t.Helper()
+	handler, err := NewHTTPHandler(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = handler.Close() })
+	return handler.(*httpHandler)
+}
+
+func httpTestRecord(level int, message string) Record {
+	return Record{
+		Level:    level,
+		Time:     httpTestTime,
+		TimeText: []byte("2026/10/19 15:04:05.000"),
+		Name:     "api",
+		Message:  []byte(message),
+		Line:     []byte("2026/10/19 15:04:05.000 INF " + message + "\n"),
+	}
+}
+
+func TestHTTPBatchBySize(t *testing.T) {
+	server := newHTTPTestServer()
+	defer server.Close()
+	h := newTestHTTPHandler(t, HTTPConfig{URL: server.URL, BatchSize: 3, BatchInterval: time.Hour})
+
+	for i := 0; i < 6; i++ {
+		_ = h.Handle(httpTestRecord(LV_INFO, "hello"))
+	}
+	server.wait(t, 2)
+	_ = h.Flush()
+	for _, body := range server.Bodies() {
+		var docs []map[string]any
+		if err := json.Unmarshal([]byte(body), &docs); err != nil {
+			t.Fatal(err)
+		}
+		if len(docs) != 3 {
+			t.Fatalf("got %d records in a batch, want 3", len(docs))
+		}
+	}
+	if stats := h.Stats(); stats.RecordsSent != 6 || stats.Requests != 2 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestHTTPBatchByInterval(t *testing.T) {
+	server := newHTTPTestServer()
+	defer server.Close()
+	h := newTestHTTPHandler(t, HTTPConfig{URL: server.URL, BatchInterval: 20 * time.Millisecond})
+
+	_ = h.Handle(httpTestRecord(LV_INFO, "a"))
+	_ = h.Handle(httpTestRecord(LV_INFO, "b"))
+	server.wait(t, 1)
+	var docs []map[string]any
+	if err := json.Unmarshal([]byte(server.Bodies()[0]), &docs); err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 2 {
+		t.Fatalf("got %d records, want 2", len(docs))
+	}
+}
+
+func TestHTTPRetry(t *testing.T) {
+	server := newHTTPTestServer(http.StatusServiceUnavailable, http.StatusInternalServerError)
+	defer server.Close()
+	h := newTestHTTPHandler(t, HTTPConfig{URL: server.URL, MinBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond})
+
+	_ = h.Handle(httpTestRecord(LV_ERROR, "retry"))
+	_ = h.Flush()
+	if stats := h.Stats(); stats.Requests != 3 || stats.Retries != 2 || stats.RecordsSent != 1 || stats.RecordsDropped != 0 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	// 4xx 不重试
+	server.lock.Lock()
+	server.status = []int{http.StatusBadRequest}
+	server.lock.Unlock()
+	_ = h.Handle(httpTestRecord(LV_ERROR, "rejected"))
+	_ = h.Flush()
+	if stats := h.Stats(); stats.Requests != 4 || stats.Retries != 2 || stats.RecordsDropped != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestHTTPFormatJSON(t *testing.T) {
+	server := newHTTPTestServer()
+	defer server.Close()
+	h := newTestHTTPHandler(t, HTTPConfig{URL: server.URL})
+
+	r := httpTestRecord(LV_WARN, "disk full")
+	r.Caller = Caller{File: "main.go", Line: 12, Func: "main"}
+	r.Fields = []Field{{Key: "n", Value: 3}, {Key: "path", Value: "/data"}}
+	r.ErrorChain = []string{"open /data", "no space left on device"}
+	_ = h.Handle(r)
+	_ = h.Flush()
+
+	want := `[{"time":"2026/10/19 15:04:05.000","level":"warn","logger":"api","caller":"main.go:12","func":"main",` +
+		`"message":"disk full","fields":{"n":3,"path":"/data"},"error.chain":["open /data","no space left on device"]}]`
+	if got := server.Bodies()[0]; got != want {
+		t.Fatalf("got %s\nwant %s", got, want)
+	}
+}
+
+func TestHTTPFormatLoki(t *testing.T) {
+	server := newHTTPTestServer()
+	defer server.Close()
+	h := newTestHTTPHandler(t, HTTPConfig{URL: server.URL, Format: FLAG_HTTP_LOKI, Labels: map[string]string{"app": "demo"}})
+
+	_ = h.Handle(httpTestRecord(LV_INFO, "a"))
+	_ = h.Handle(httpTestRecord(LV_ERROR, "b"))
+	_ = h.Handle(httpTestRecord(LV_INFO, "c"))
+	_ = h.Flush()
+
+	var body struct {
+		Streams []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		} `json:"streams"`
+	}
+	if err := json.Unmarshal([]byte(server.Bodies()[0]), &body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Streams) != 2 {
+		t.Fatalf("got %d streams, want 2", len(body.Streams))
+	}
+	info := body.Streams[0]
+	if info.Stream["app"] != "demo" || info.Stream["level"] != "info" || info.Stream["logger"] != "api" {
+		t.Fatalf("unexpected labels %v", info.Stream)
+	}
+	if len(info.Values) != 2 || info.Values[0][0] != "1792422245000000000" ||
+		info.Values[0][1] != "2026/10/19 15:04:05.000 INF a" {
+		t.Fatalf("unexpected values %v", info.Values)
+	}
+	if body.Streams[1].Stream["level"] != "error" || len(body.Streams[1].Values) != 1 {
+		t.Fatalf("unexpected stream %v", body.Streams[1])
+	}
+}
+
+func TestHTTPFormatElastic(t *testing.T) {
+	server := newHTTPTestServer()
+	defer server.Close()
+	h := newTestHTTPHandler(t, HTTPConfig{URL: server.URL, Format: FLAG_HTTP_ELASTIC, Index: "logs"})
+
+	_ = h.Handle(httpTestRecord(LV_INFO, "a"))
+	_ = h.Handle(httpTestRecord(LV_INFO, "b"))
+	_ = h.Flush()
+
+	lines := strings.Split(server.Bodies()[0], "\n")
+	if len(lines) != 5 || lines[4] != "" {
+		t.Fatalf("unexpected body %q", server.Bodies()[0])
+	}
+	for i := 0; i < 4; i += 2 {
+		if lines[i] != `{"create":{"_index":"logs"}}` {
+			t.Fatalf("unexpected action %s", lines[i])
+		}
+		var doc map[string]any
+		if err := json.Unmarshal([]byte(lines[i+1]), &doc); err != nil {
+			t.Fatal(err)
+		}
+		if doc["@timestamp"] != "2026-10-19T15:04:05Z" || doc["level"] != "info" {
+			t.Fatalf("unexpected document %s", lines[i+1])
+		}
+	}
+}
+
+func TestHTTPCloseFlushes(t *testing.T) {
+	server := newHTTPTestServer()
+	defer server.Close()
+	handler, err := NewHTTPHandler(HTTPConfig{URL: server.URL, BatchInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := handler.(*httpHandler)
+	for i := 0; i < 5; i++ {
+		_ = h.Handle(httpTestRecord(LV_INFO, "pending"))
+	}
+	if err = h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if stats := h.Stats(); stats.RecordsSent != 5 || stats.Requests != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	if err = h.Handle(httpTestRecord(LV_INFO, "late")); err == nil {
+		t.Fatal("Handle after Close should fail")
+	}
+}
+
+func TestHTTPLoggerSanitizesFields(t *testing.T) {
+	server := newHTTPTestServer()
+	defer server.Close()
+	logger, err := NewHTTPLogger(HTTPConfig{URL: server.URL},
+		WithMaxFieldBytes(8), WithErrorChain(true), WithTimeStyle(FLAG_TIME_TIMESTAMP))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.With("color", "\x1b[31mred\x1b[0m", "long", strings.Repeat("x", 20)).
+		Error("failed", fmt.Errorf("query: %w", errors.New("timeout")))
+	_ = logger.Close()
+
+	var docs []struct {
+		Time   string            `json:"time"`
+		Fields map[string]string `json:"fields"`
+		Chain  []string          `json:"error.chain"`
+	}
+	if err = json.Unmarshal([]byte(server.Bodies()[0]), &docs); err != nil {
+		t.Fatal(err)
+	}
+	doc := docs[0]
+	if _, err = strconv.ParseInt(doc.Time, 10, 64); err != nil {
+		t.Fatalf("time %q does not follow WithTimeStyle", doc.Time)
+	}
+	if doc.Fields["color"] != "red" || doc.Fields["long"] != "xxxxxxxx…(truncated 12 bytes)" {
+		t.Fatalf("unexpected fields %v", doc.Fields)
+	}
+	if len(doc.Chain) != 1 || doc.Chain[0] != "timeout" {
+		t.Fatalf("unexpected error chain %v", doc.Chain)
+	}
+}
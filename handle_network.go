@@ -0,0 +1,252 @@
+package log
+
+import (
+	"crypto/tls"
+	"encoding/binary"
+	"math/rand"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// NetworkConfig 网络 handler 的配置
+type NetworkConfig struct {
+	// tcp、udp、unix、unixgram
+	Network string
+	Address string
+	// 流式连接的分帧方式，数据报每条日志为一个报文
+	Framing FLAG_FRAMING
+	// 不为空时使用 TLS(仅 tcp)
+	TLS *tls.Config
+	// 连接及写入超时，默认 5s
+	Timeout time.Duration
+	// 重连的退避时间，默认 100ms ~ 30s
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// 连接断开期间的缓存: SpillPath 不为空时缓存到文件，否则缓存在内存
+	SpillPath string
+	// 缓存的最大字节数，默认内存 8M，文件 256M，超过时丢弃新的日志
+	SpillBytes int64
+}
+
+// NetworkStats 网络 handler 的计数
+type NetworkStats struct {
+	BytesSent      uint64
+	RecordsSent    uint64
+	RecordsDropped uint64
+	Reconnects     uint64
+	// 尚未发送的缓存字节数
+	Pending int64
+}
+
+// INetworkStats 网络 handler 的计数
+type INetworkStats interface {
+	Stats() NetworkStats
+}
+
+// networkHandler 将日志发送到 TCP/UDP/Unix 套接字
+//
+// 连接断开时写入缓存并在后台按指数退避重连，连接成功后先发送缓存再继续写入，Write 不会等待重连
+type networkHandler struct {
+	lock     sync.Mutex
+	config   NetworkConfig
+	conn     net.Conn
+	datagram bool
+	spill    spill
+	frame    []byte
+	// 后台重连中
+	reconnecting bool
+	closed       bool
+	stop         chan struct{}
+
+	bytesSent      atomic.Uint64
+	recordsSent    atomic.Uint64
+	recordsDropped atomic.Uint64
+	reconnects     atomic.Uint64
+}
+
+func (h *networkHandler) Write(b []byte) (n int, err error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if h.closed {
+		return 0, os.ErrClosed
+	}
+	h.frame = h.appendFrame(h.frame[:0], b)
+	if h.conn != nil && h.spill.size() == 0 {
+		if h.datagram || connAlive(h.conn) {
+			if err = h.send(h.frame); err == nil {
+				return len(b), nil
+			}
+		}
+		h.closeConn()
+	}
+	if !h.spill.push(h.frame) {
+		h.recordsDropped.Add(1)
+	}
+	h.startReconnect()
+	return len(b), nil
+}
+
+func (h *networkHandler) Stats() NetworkStats {
+	h.lock.Lock()
+	pending := h.spill.size()
+	h.lock.Unlock()
+	return NetworkStats{
+		BytesSent:      h.bytesSent.Load(),
+		RecordsSent:    h.recordsSent.Load(),
+		RecordsDropped: h.recordsDropped.Load(),
+		Reconnects:     h.reconnects.Load(),
+		Pending:        pending,
+	}
+}
+
+// Close 已连接时先发送缓存，内存中未发送的日志计入丢弃
+func (h *networkHandler) Close() (err error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if h.closed {
+		return nil
+	}
+	h.closed = true
+	close(h.stop)
+	if h.conn != nil {
+		_ = h.spill.drain(h.send)
+		h.closeConn()
+	}
+	lost, err := h.spill.close()
+	h.recordsDropped.Add(uint64(lost))
+	return err
+}
+
+// appendFrame 数据报及换行分帧保留结尾的换行，长度前缀分帧不含换行
+func (h *networkHandler) appendFrame(frame, b []byte) []byte {
+	if h.datagram {
+		return append(frame, b...)
+	}
+	if h.config.Framing == FLAG_FRAMING_LENGTH {
+		b = trimNewline(b, 0)
+		frame = binary.BigEndian.AppendUint32(frame, uint32(len(b)))
+		return append(frame, b...)
+	}
+	frame = append(frame, b...)
+	if len(frame) == 0 || frame[len(frame)-1] != 0x0a {
+		frame = append(frame, 0x0a)
+	}
+	return frame
+}
+
+// send 发送一帧，调用方需持有锁
+func (h *networkHandler) send(frame []byte) error {
+	_ = h.conn.SetWriteDeadline(time.Now().Add(h.config.Timeout))
+	n, err := h.conn.Write(frame)
+	h.bytesSent.Add(uint64(n))
+	if err != nil {
+		return err
+	}
+	h.recordsSent.Add(1)
+	return nil
+}
+
+func (h *networkHandler) closeConn() {
+	if h.conn != nil {
+		_ = h.conn.Close()
+		h.conn = nil
+	}
+}
+
+func (h *networkHandler) startReconnect() {
+	if h.reconnecting || h.closed {
+		return
+	}
+	h.reconnecting = true
+	go h.reconnect()
+}
+
+// reconnect 按指数退避(加随机抖动)重连，连接成功后发送缓存
+func (h *networkHandler) reconnect() {
+	backoff := h.config.MinBackoff
+	for {
+		conn, err := h.dial()
+		if err == nil {
+			h.lock.Lock()
+			if h.closed {
+				h.reconnecting = false
+				h.lock.Unlock()
+				_ = conn.Close()
+				return
+			}
+			h.conn = conn
+			h.reconnects.Add(1)
+			if err = h.spill.drain(h.send); err == nil {
+				h.reconnecting = false
+				h.lock.Unlock()
+				return
+			}
+			h.closeConn()
+			h.lock.Unlock()
+		}
+		wait := backoff/2 + time.Duration(rand.Int63n(int64(backoff)))
+		select {
+		case <-h.stop:
+			return
+		case <-time.After(wait):
+		}
+		if backoff *= 2; backoff > h.config.MaxBackoff {
+			backoff = h.config.MaxBackoff
+		}
+	}
+}
+
+func (h *networkHandler) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: h.config.Timeout}
+	if h.config.TLS != nil {
+		return tls.DialWithDialer(dialer, h.config.Network, h.config.Address, h.config.TLS)
+	}
+	return dialer.Dial(h.config.Network, h.config.Address)
+}
+
+// NewNetworkHandler 创建网络 handler，首次连接在后台进行，连接成功前的日志写入缓存
+func NewNetworkHandler(config NetworkConfig) (IHandler, error) {
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Second
+	}
+	if config.MinBackoff <= 0 {
+		config.MinBackoff = 100 * time.Millisecond
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = 30 * time.Second
+	}
+	if config.MaxBackoff < config.MinBackoff {
+		config.MaxBackoff = config.MinBackoff
+	}
+	h := &networkHandler{
+		config:   config,
+		datagram: strings.HasPrefix(config.Network, "udp") || config.Network == "unixgram",
+		stop:     make(chan struct{}),
+	}
+	if config.SpillPath != "" {
+		spill, err := newDiskSpill(config.SpillPath, config.SpillBytes)
+		if err != nil {
+			return nil, err
+		}
+		h.spill = spill
+	} else {
+		h.spill = newMemorySpill(config.SpillBytes)
+	}
+	h.lock.Lock()
+	h.startReconnect()
+	h.lock.Unlock()
+	return h, nil
+}
+
+// NewNetworkLogger 创建输出到网络的 Logger，默认不输出颜色
+func NewNetworkLogger(config NetworkConfig, opts ...Option) (*Logger, error) {
+	handler, err := NewNetworkHandler(config)
+	if err != nil {
+		return nil, err
+	}
+	return New(handler, append([]Option{WithColorMode(FLAG_COLOR_NEVER)}, opts...)...), nil
+}
@@ -0,0 +1,227 @@
+package log
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func networkTestConfig(address string) NetworkConfig {
+	return NetworkConfig{
+		Network:    "tcp",
+		Address:    address,
+		Timeout:    time.Second,
+		MinBackoff: 5 * time.Millisecond,
+		MaxBackoff: 20 * time.Millisecond,
+	}
+}
+
+func newTestNetworkHandler(t *testing.T, config NetworkConfig) *networkHandler {
+	t.Helper()
+	handler, err := NewNetworkHandler(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = handler.Close() })
+	return handler.(*networkHandler)
+}
+
+// closedAddress 返回一个没有监听的本地地址
+func closedAddress(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	address := listener.Addr().String()
+	_ = listener.Close()
+	return address
+}
+
+func accept(t *testing.T, listener net.Listener) net.Conn {
+	t.Helper()
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	return conn
+}
+
+func readLines(t *testing.T, r *bufio.Reader, want ...string) {
+	t.Helper()
+	for _, line := range want {
+		got, err := r.ReadString(0x0a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != line {
+			t.Fatalf("got %q, want %q", got, line)
+		}
+	}
+}
+
+func TestNetworkLengthFraming(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer listener.Close()
+	config := networkTestConfig(listener.Addr().String())
+	config.Framing = FLAG_FRAMING_LENGTH
+	h := newTestNetworkHandler(t, config)
+	conn := accept(t, listener)
+
+	want := []string{"first", "multi\nline", ""}
+	for _, msg := range want {
+		_, _ = h.Write([]byte(msg + "\n"))
+	}
+	r := bufio.NewReader(conn)
+	for _, msg := range want {
+		var head [4]byte
+		if _, err = io.ReadFull(r, head[:]); err != nil {
+			t.Fatal(err)
+		}
+		frame := make([]byte, binary.BigEndian.Uint32(head[:]))
+		if _, err = io.ReadFull(r, frame); err != nil {
+			t.Fatal(err)
+		}
+		if string(frame) != msg {
+			t.Fatalf("got %q, want %q", frame, msg)
+		}
+	}
+}
+
+func TestNetworkReconnectDrainsInOrder(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	address := listener.Addr().String()
+	h := newTestNetworkHandler(t, networkTestConfig(address))
+	conn := accept(t, listener)
+	_, _ = h.Write([]byte("1\n"))
+	readLines(t, bufio.NewReader(conn), "1\n")
+
+	// 断开连接，重连失败期间的日志写入缓存
+	_ = listener.Close()
+	_ = conn.Close()
+	time.Sleep(20 * time.Millisecond)
+	for _, line := range []string{"2\n", "3\n", "4\n"} {
+		_, _ = h.Write([]byte(line))
+	}
+	if stats := h.Stats(); stats.Pending != 6 || stats.RecordsSent != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	if listener, err = net.Listen("tcp", address); err != nil {
+		t.Skip(err)
+	}
+	defer listener.Close()
+	r := bufio.NewReader(accept(t, listener))
+	readLines(t, r, "2\n", "3\n", "4\n")
+	_, _ = h.Write([]byte("5\n"))
+	readLines(t, r, "5\n")
+	if stats := h.Stats(); stats.Pending != 0 || stats.RecordsSent != 5 || stats.Reconnects != 2 || stats.RecordsDropped != 0 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestNetworkSpillOverflow(t *testing.T) {
+	config := networkTestConfig(closedAddress(t))
+	config.SpillBytes = 10
+	h := newTestNetworkHandler(t, config)
+
+	for i := 0; i < 3; i++ {
+		_, _ = h.Write([]byte("abcd\n"))
+	}
+	if stats := h.Stats(); stats.RecordsDropped != 1 || stats.Pending != 10 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	// 关闭时内存中未发送的日志计入丢弃
+	_ = h.Close()
+	if stats := h.Stats(); stats.RecordsDropped != 3 || stats.Pending != 0 {
+		t.Fatalf("unexpected stats after Close %+v", stats)
+	}
+}
+
+func TestNetworkDiskSpillReopened(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spill.log")
+	config := networkTestConfig(closedAddress(t))
+	config.SpillPath = path
+	h := newTestNetworkHandler(t, config)
+	_, _ = h.Write([]byte("a\n"))
+	_, _ = h.Write([]byte("bb\n"))
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if stats := h.Stats(); stats.RecordsDropped != 0 {
+		t.Fatalf("disk spill should keep records, got %+v", stats)
+	}
+
+	// 重新打开缓存文件后先发送缓存的日志
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer listener.Close()
+	config.Address = listener.Addr().String()
+	h = newTestNetworkHandler(t, config)
+	r := bufio.NewReader(accept(t, listener))
+	readLines(t, r, "a\n", "bb\n")
+	_, _ = h.Write([]byte("c\n"))
+	readLines(t, r, "c\n")
+	if stat, err := os.Stat(path); err != nil || stat.Size() != 0 {
+		t.Fatalf("spill file not truncated: %v %v", stat, err)
+	}
+}
+
+func TestDiskSpillPartialDrain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spill.log")
+	d, err := newDiskSpill(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, frame := range []string{"a", "bb", "ccc"} {
+		if !d.push([]byte(frame)) {
+			t.Fatalf("push %q failed", frame)
+		}
+	}
+	// 发送失败时保留尚未发送的帧
+	var sent []string
+	failed := errors.New("broken pipe")
+	err = d.drain(func(frame []byte) error {
+		if len(sent) == 1 {
+			return failed
+		}
+		sent = append(sent, string(frame))
+		return nil
+	})
+	if err != failed || d.size() != 4+2+4+3 {
+		t.Fatalf("got %v with %d bytes left", err, d.size())
+	}
+	if _, err = d.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 重新打开后从文件开头发送(已发送的帧会重复)
+	if d, err = newDiskSpill(path, 0); err != nil {
+		t.Fatal(err)
+	}
+	defer d.close()
+	sent = sent[:0]
+	err = d.drain(func(frame []byte) error {
+		sent = append(sent, string(frame))
+		return nil
+	})
+	if err != nil || len(sent) != 3 || sent[0] != "a" || sent[2] != "ccc" || d.size() != 0 {
+		t.Fatalf("got %v %q, %d bytes left", err, sent, d.size())
+	}
+}
@@ -0,0 +1,125 @@
+package log
+
+import (
+	"errors"
+	"time"
+)
+
+// Route 路由规则，级别在 [MinLevel, MaxLevel] 之间且满足 Name、Match 条件的日志写入 Handler
+//
+// eg: Route{MinLevel: LV_ERROR, MaxLevel: LV_FATAL, Handler: errorFile}
+type Route struct {
+	MinLevel int
+	MaxLevel int
+	// 非空时只匹配该名称的 Logger，见 WithName
+	Name string
+	// 可选，按字段等自定义条件匹配
+	Match   func(r Record) bool
+	Handler IHandler
+}
+
+func (r *Route) match(record Record) bool {
+	if record.Level < r.MinLevel || record.Level > r.MaxLevel {
+		return false
+	}
+	if r.Name != "" && r.Name != record.Name {
+		return false
+	}
+	return r.Match == nil || r.Match(record)
+}
+
+// routerHandler 按级别等条件将日志写入不同的 handler，一条日志可匹配多个路由
+type routerHandler struct {
+	routes []Route
+}
+
+// Handle 写入所有匹配的路由
+func (h *routerHandler) Handle(r Record) error {
+	var errs []error
+	for i := range h.routes {
+		route := &h.routes[i]
+		if !route.match(r) {
+			continue
+		}
+		if err := writeRecord(route.Handler, r); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Enabled 是否有路由接收该级别的日志
+func (h *routerHandler) Enabled(level int) bool {
+	for i := range h.routes {
+		if level >= h.routes[i].MinLevel && level <= h.routes[i].MaxLevel {
+			return true
+		}
+	}
+	return false
+}
+
+// Write 没有级别信息(如被其他 handler 包装)时以 LV_PRINT 按路由写入
+func (h *routerHandler) Write(b []byte) (n int, err error) {
+	return len(b), h.Handle(Record{Level: LV_PRINT, Time: time.Now(), Line: b})
+}
+
+func (h *routerHandler) Flush() (err error) {
+	var errs []error
+	for _, handler := range h.handlers() {
+		if f, ok := findHandler[IFlusher](handler); ok {
+			errs = append(errs, f.Flush())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h *routerHandler) Close() (err error) {
+	var errs []error
+	for _, handler := range h.handlers() {
+		errs = append(errs, handler.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// handlers 去重后的 handler，同一 handler 可用于多个路由
+func (h *routerHandler) handlers() []IHandler {
+	result := make([]IHandler, 0, len(h.routes))
+	for _, route := range h.routes {
+		seen := false
+		for _, handler := range result {
+			if handler == route.Handler {
+				seen = true
+				break
+			}
+		}
+		if !seen {
+			result = append(result, route.Handler)
+		}
+	}
+	return result
+}
+
+// writeRecord 优先使用 RecordHandler 保留级别等信息
+func writeRecord(handler IHandler, r Record) error {
+	if rh, ok := handler.(RecordHandler); ok {
+		if !rh.Enabled(r.Level) {
+			return nil
+		}
+		return rh.Handle(r)
+	}
+	_, err := handler.Write(r.Line)
+	return err
+}
+
+// NewRouterHandler 按路由规则将日志写入不同的 handler
+//
+// eg: 错误写入 error.log，全部写入 app.log，调试日志只输出到终端
+//
+//	NewRouterHandler(
+//		Route{MinLevel: LV_ERROR, MaxLevel: LV_FATAL, Handler: errorFile},
+//		Route{MinLevel: LV_DEBUG, MaxLevel: LV_FATAL, Handler: appFile},
+//		Route{MinLevel: LV_DEBUG, MaxLevel: LV_DEBUG, Handler: os.Stdout},
+//	)
+func NewRouterHandler(routes ...Route) IHandler {
+	return &routerHandler{routes: routes}
+}
@@ -0,0 +1,40 @@
+package log
+
+import "testing"
+
+func TestRouterWriteUsesPrintLevel(t *testing.T) {
+	errors, all := &bufferHandler{}, &bufferHandler{}
+	router := NewRouterHandler(
+		Route{MinLevel: LV_ERROR, MaxLevel: LV_FATAL, Handler: errors},
+		Route{MinLevel: LV_DEBUG, MaxLevel: LV_FATAL, Handler: all},
+	)
+	if _, err := router.Write([]byte("plain\n")); err != nil {
+		t.Fatal(err)
+	}
+	if got := errors.String(); got != "" {
+		t.Fatalf("error route got %q, want nothing", got)
+	}
+	if got, want := all.String(), "plain\n"; got != want {
+		t.Fatalf("all route got %q, want %q", got, want)
+	}
+}
+
+func TestRouterHandleMatchesLevelAndName(t *testing.T) {
+	errors, db := &bufferHandler{}, &bufferHandler{}
+	router := NewRouterHandler(
+		Route{MinLevel: LV_ERROR, MaxLevel: LV_FATAL, Handler: errors},
+		Route{MinLevel: LV_DEBUG, MaxLevel: LV_FATAL, Name: "db", Handler: db},
+	).(RecordHandler)
+	_ = router.Handle(Record{Level: LV_INFO, Name: "api", Line: []byte("a\n")})
+	_ = router.Handle(Record{Level: LV_ERROR, Name: "db", Line: []byte("b\n")})
+	_ = router.Handle(Record{Level: LV_INFO, Name: "db", Line: []byte("c\n")})
+	if got, want := errors.String(), "b\n"; got != want {
+		t.Fatalf("error route got %q, want %q", got, want)
+	}
+	if got, want := db.String(), "b\nc\n"; got != want {
+		t.Fatalf("db route got %q, want %q", got, want)
+	}
+	if router.Enabled(LV_DEBUG - 1) {
+		t.Fatal("no route accepts levels below LV_DEBUG")
+	}
+}
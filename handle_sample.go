@@ -0,0 +1,237 @@
+package log
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ISampler 采样 handler 的计数
+type ISampler interface {
+	// Stats 返回已写入及被丢弃的日志条数
+	Stats() (passed, suppressed uint64)
+}
+
+type sampleEntry struct {
+	count      int
+	suppressed uint64
+	// 最近一条被丢弃的日志，用于生成摘要
+	level   int
+	caller  Caller
+	name    string
+	message []byte
+	line    []byte
+}
+
+// samplingHandler 限制重复日志的数量
+//
+// 以级别、调用位置及消息内容作为 key(只有 Write 时为去除颜色的整行)，
+// 每个周期内同一 key 先写入 first 条，之后每 thereafter 条写入 1 条(thereafter < 1 时全部丢弃)。
+// 周期结束时为有丢弃的 key 写入一条摘要: "suppressed 12,345 similar messages: <最近一条被丢弃的日志>"
+type samplingHandler struct {
+	handler    IHandler
+	first      int
+	thereafter int
+
+	lock    sync.Mutex
+	entries map[uint64]*sampleEntry
+	scratch []byte
+	summary []byte
+	message []byte
+
+	passed     atomic.Uint64
+	suppressed atomic.Uint64
+
+	stop   chan struct{}
+	closed atomic.Bool
+}
+
+func (s *samplingHandler) Handle(r Record) error {
+	s.lock.Lock()
+	entry, pass := s.sample(recordKey(r))
+	if !pass {
+		entry.level, entry.caller, entry.name = r.Level, r.Caller, r.Name
+		entry.message = append(entry.message[:0], r.Message...)
+		entry.line = append(entry.line[:0], r.Line...)
+	}
+	s.lock.Unlock()
+	if !pass {
+		s.suppressed.Add(1)
+		return nil
+	}
+	s.passed.Add(1)
+	return writeRecord(s.handler, r)
+}
+
+func (s *samplingHandler) Enabled(level int) bool {
+	if rh, ok := s.handler.(RecordHandler); ok {
+		return rh.Enabled(level)
+	}
+	return true
+}
+
+// Write 没有级别等信息时以去除颜色的整行作为 key
+func (s *samplingHandler) Write(b []byte) (n int, err error) {
+	s.lock.Lock()
+	s.scratch = StripANSI(append(s.scratch[:0], b...))
+	entry, pass := s.sample(sampleKey(s.scratch))
+	if !pass {
+		entry.level, entry.caller, entry.name = LV_PRINT, Caller{}, ""
+		entry.message = append(entry.message[:0], trimNewline(s.scratch, 0)...)
+		entry.line = append(entry.line[:0], s.scratch...)
+	}
+	s.lock.Unlock()
+	if !pass {
+		s.suppressed.Add(1)
+		return len(b), nil
+	}
+	s.passed.Add(1)
+	return s.handler.Write(b)
+}
+
+// sample 计数并判断是否写入，调用方需持有锁
+func (s *samplingHandler) sample(key uint64) (*sampleEntry, bool) {
+	entry, ok := s.entries[key]
+	if !ok {
+		entry = &sampleEntry{}
+		s.entries[key] = entry
+	}
+	entry.count++
+	pass := entry.count <= s.first ||
+		(s.thereafter > 0 && (entry.count-s.first)%s.thereafter == 0)
+	if !pass {
+		entry.suppressed++
+	}
+	return entry, pass
+}
+
+func (s *samplingHandler) Stats() (passed, suppressed uint64) {
+	return s.passed.Load(), s.suppressed.Load()
+}
+
+// tick 结束当前周期: 写入摘要并重置计数，清理整个周期内未出现的 key
+func (s *samplingHandler) tick() (err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := time.Now()
+	for key, entry := range s.entries {
+		if entry.count == 0 {
+			delete(s.entries, key)
+			continue
+		}
+		if entry.suppressed > 0 {
+			s.summary = appendSampleSummary(s.summary[:0], entry.line, entry.suppressed)
+			s.message = appendSampleSummary(s.message[:0], entry.message, entry.suppressed)
+			e := writeRecord(s.handler, Record{
+				Level:   entry.level,
+				Time:    now,
+				Caller:  entry.caller,
+				Name:    entry.name,
+				Message: trimNewline(s.message, 0),
+				Line:    s.summary,
+			})
+			if err == nil {
+				err = e
+			}
+		}
+		entry.count = 0
+		entry.suppressed = 0
+	}
+	return err
+}
+
+// Flush 刷新下游的缓冲区，周期只在定时器及 Close 时结束
+func (s *samplingHandler) Flush() (err error) {
+	if f, ok := s.handler.(IFlusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+func (s *samplingHandler) Close() (err error) {
+	if s.closed.Swap(true) {
+		return nil
+	}
+	close(s.stop)
+	_ = s.tick()
+	return s.handler.Close()
+}
+
+func (s *samplingHandler) Unwrap() IHandler {
+	return s.handler
+}
+
+// appendSampleSummary 丢弃条数与原日志内容
+func appendSampleSummary(b, line []byte, suppressed uint64) []byte {
+	b = append(b, "suppressed "...)
+	b = appendThousands(b, suppressed)
+	b = append(b, " similar messages: "...)
+	b = append(b, trimNewline(line, 0)...)
+	return append(b, '\n')
+}
+
+// appendThousands 按千分位输出数字，eg: 12,345
+func appendThousands(b []byte, n uint64) []byte {
+	var tmp [20]byte
+	digits := strconv.AppendUint(tmp[:0], n, 10)
+	for i, c := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b = append(b, ',')
+		}
+		b = append(b, c)
+	}
+	return b
+}
+
+const (
+	fnvOffset = 14695981039346656037
+	fnvPrime  = 1099511628211
+)
+
+// sampleKey FNV-1a 64
+func sampleKey(b []byte) uint64 {
+	return fnvBytes(fnvOffset, b)
+}
+
+// recordKey 由级别、调用位置及消息内容计算 key
+func recordKey(r Record) uint64 {
+	hash := (fnvOffset ^ uint64(r.Level)) * fnvPrime
+	hash = fnvString(hash, r.Caller.File)
+	hash = (hash ^ uint64(r.Caller.Line)) * fnvPrime
+	return fnvBytes(hash, r.Message)
+}
+
+func fnvBytes(hash uint64, b []byte) uint64 {
+	for _, c := range b {
+		hash ^= uint64(c)
+		hash *= fnvPrime
+	}
+	return hash
+}
+
+func fnvString(hash uint64, s string) uint64 {
+	for i := 0; i < len(s); i++ {
+		hash ^= uint64(s[i])
+		hash *= fnvPrime
+	}
+	return hash
+}
+
+// NewSamplingHandler 包装 handler，每个 interval 内同一日志先写入 first 条，之后每 thereafter 条写入 1 条
+//
+// eg: NewSamplingHandler(handler, time.Second, 10, 100)
+func NewSamplingHandler(handler IHandler, interval time.Duration, first, thereafter int) IHandler {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	s := &samplingHandler{
+		handler:    handler,
+		first:      first,
+		thereafter: thereafter,
+		entries:    make(map[uint64]*sampleEntry),
+		stop:       make(chan struct{}),
+	}
+	go runFlusher(interval, s.stop, s.tick)
+	return s
+}
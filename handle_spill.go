@@ -0,0 +1,165 @@
+package log
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"os"
+)
+
+// 默认的缓存大小
+const (
+	defaultMemorySpillBytes = 8 * 1024 * 1024
+	defaultDiskSpillBytes   = 256 * 1024 * 1024
+)
+
+// spill 连接断开期间缓存已分帧的日志，调用方需持有 handler 的锁
+type spill interface {
+	// push 缓存一帧，超过容量时返回 false
+	push(frame []byte) bool
+	// drain 依次发送缓存的帧，send 失败时保留尚未发送的帧
+	drain(send func(frame []byte) error) error
+	// size 尚未发送的字节数
+	size() int64
+	// close 关闭缓存，返回丢失的帧数
+	close() (lost int, err error)
+}
+
+// memorySpill 内存缓存，进程退出时丢失
+type memorySpill struct {
+	frames [][]byte
+	bytes  int64
+	max    int64
+}
+
+func newMemorySpill(max int64) *memorySpill {
+	if max < 1 {
+		max = defaultMemorySpillBytes
+	}
+	return &memorySpill{max: max}
+}
+
+func (m *memorySpill) push(frame []byte) bool {
+	if m.bytes+int64(len(frame)) > m.max {
+		return false
+	}
+	m.frames = append(m.frames, append([]byte(nil), frame...))
+	m.bytes += int64(len(frame))
+	return true
+}
+
+func (m *memorySpill) drain(send func(frame []byte) error) error {
+	for len(m.frames) > 0 {
+		if err := send(m.frames[0]); err != nil {
+			return err
+		}
+		m.bytes -= int64(len(m.frames[0]))
+		m.frames[0] = nil
+		m.frames = m.frames[1:]
+	}
+	m.frames = nil
+	return nil
+}
+
+func (m *memorySpill) size() int64 {
+	return m.bytes
+}
+
+func (m *memorySpill) close() (lost int, err error) {
+	lost = len(m.frames)
+	m.frames, m.bytes = nil, 0
+	return lost, nil
+}
+
+// diskSpill 文件缓存，未发送的内容在下次启动后继续发送(已部分发送的文件会重复发送)
+//
+// 文件中每帧以 4 字节长度前缀保存，数据报及换行分帧中的换行不影响读取
+type diskSpill struct {
+	fd *os.File
+	// 已发送的位置及文件大小
+	offset int64
+	bytes  int64
+	max    int64
+	record []byte
+}
+
+func newDiskSpill(path string, max int64) (*diskSpill, error) {
+	if max < 1 {
+		max = defaultDiskSpillBytes
+	}
+	fd, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := fd.Stat()
+	if err != nil {
+		fd.Close()
+		return nil, err
+	}
+	return &diskSpill{fd: fd, bytes: stat.Size(), max: max}, nil
+}
+
+func (d *diskSpill) push(frame []byte) bool {
+	if d.size()+int64(4+len(frame)) > d.max {
+		return false
+	}
+	// 长度前缀与帧一次写入，进程退出时最多留下一个不完整的帧
+	d.record = binary.BigEndian.AppendUint32(d.record[:0], uint32(len(frame)))
+	d.record = append(d.record, frame...)
+	n, err := d.fd.Write(d.record)
+	d.bytes += int64(n)
+	return err == nil
+}
+
+// drain 逐帧读取并发送，全部发送后清空文件；不完整的帧(如写入时进程退出)被丢弃
+func (d *diskSpill) drain(send func(frame []byte) error) error {
+	if d.size() == 0 {
+		return nil
+	}
+	r := bufio.NewReaderSize(io.NewSectionReader(d.fd, d.offset, d.size()), 64*1024)
+	var frame []byte
+	var err error
+	for {
+		frame, err = readSpillFrame(r, frame[:0])
+		if err != nil {
+			break
+		}
+		if err = send(frame); err != nil {
+			return err
+		}
+		d.offset += int64(4 + len(frame))
+	}
+	d.offset, d.bytes = 0, 0
+	return d.fd.Truncate(0)
+}
+
+// readSpillFrame 读取一帧，返回的内容不含长度前缀
+func readSpillFrame(r *bufio.Reader, frame []byte) ([]byte, error) {
+	var head [4]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return frame, err
+	}
+	n := int(binary.BigEndian.Uint32(head[:]))
+	for n > 0 {
+		size := n
+		if size > r.Size() {
+			size = r.Size()
+		}
+		chunk, err := r.Peek(size)
+		if err != nil {
+			return frame, err
+		}
+		frame = append(frame, chunk...)
+		_, _ = r.Discard(len(chunk))
+		n -= len(chunk)
+	}
+	return frame, nil
+}
+
+func (d *diskSpill) size() int64 {
+	return d.bytes - d.offset
+}
+
+func (d *diskSpill) close() (lost int, err error) {
+	return 0, d.fd.Close()
+}
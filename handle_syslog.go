@@ -0,0 +1,247 @@
+package log
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 未指定地址时依次尝试的本地 syslog 套接字
+var syslogLocalPaths = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// LV_* 对应的 syslog severity
+var syslogSeverity = map[int]int{
+	LV_DEBUG: 7, // debug
+	LV_PRINT: 5, // notice
+	LV_INFO:  6, // info
+	LV_WARN:  4, // warning
+	LV_ERROR: 3, // err
+	LV_PANIC: 2, // crit
+	LV_FATAL: 1, // alert
+}
+
+// SyslogConfig syslog handler 的配置
+type SyslogConfig struct {
+	// unix、unixgram、udp、tcp，为空时使用本地套接字
+	Network string
+	// 为空时依次尝试 /dev/log、/var/run/syslog、/var/run/log
+	Address string
+	Format  FLAG_SYSLOG
+	// 为 0(KERN) 时使用 USER，应用程序不能使用 KERN
+	Facility SYSLOG_FACILITY
+	// 默认为程序名
+	AppName string
+	// 默认为主机名
+	Hostname string
+	// 默认为进程 ID
+	ProcID string
+	// 连接及写入超时，默认 5s
+	Timeout time.Duration
+}
+
+// syslogHandler 将日志发送到 syslog，连接断开后在下次写入时重连
+//
+// 消息内容为去除颜色及换行的整行日志，RFC 5424 的 MSGID 为 Logger 的名称，见 NewSyslogLogger
+type syslogHandler struct {
+	lock   sync.Mutex
+	config SyslogConfig
+	conn   net.Conn
+	// 流式连接: tcp 使用 octet counting 分帧，unix 以换行分隔
+	stream bool
+	buf    []byte
+	frame  []byte
+	closed bool
+}
+
+func (s *syslogHandler) Handle(r Record) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		return os.ErrClosed
+	}
+	s.buf = s.appendMessage(s.buf[:0], r)
+	return s.send()
+}
+
+func (s *syslogHandler) Enabled(level int) bool {
+	return true
+}
+
+// Write 没有级别信息时以 LV_PRINT(notice) 发送
+func (s *syslogHandler) Write(b []byte) (n int, err error) {
+	return len(b), s.Handle(Record{Level: LV_PRINT, Time: time.Now(), Line: b})
+}
+
+func (s *syslogHandler) Close() (err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.closed = true
+	if s.conn != nil {
+		err = s.conn.Close()
+		s.conn = nil
+	}
+	return err
+}
+
+func (s *syslogHandler) appendMessage(b []byte, r Record) []byte {
+	severity, ok := syslogSeverity[r.Level]
+	if !ok {
+		severity = 5
+	}
+	b = append(b, '<')
+	b = strconv.AppendInt(b, int64(int(s.config.Facility)*8+severity), 10)
+	b = append(b, '>')
+	now := r.Time
+	if s.config.Format == FLAG_SYSLOG_RFC3164 {
+		b = now.AppendFormat(b, time.Stamp)
+		b = append(b, 0x20)
+		b = append(b, s.config.Hostname...)
+		b = append(b, 0x20)
+		b = append(b, s.config.AppName...)
+		b = append(b, '[')
+		b = append(b, s.config.ProcID...)
+		b = append(b, "]: "...)
+	} else {
+		b = append(b, "1 "...)
+		b = now.AppendFormat(b, "2006-01-02T15:04:05.000000Z07:00")
+		b = append(b, 0x20)
+		b = appendSyslogField(b, s.config.Hostname, 255)
+		b = append(b, 0x20)
+		b = appendSyslogField(b, s.config.AppName, 48)
+		b = append(b, 0x20)
+		b = appendSyslogField(b, s.config.ProcID, 128)
+		b = append(b, 0x20)
+		b = appendSyslogField(b, r.Name, 32)
+		b = append(b, " - "...)
+	}
+	n := len(b)
+	b = StripANSI(append(b, r.Line...))
+	b = trimNewline(b, n)
+	if s.stream && !s.octet() {
+		// 以换行分帧时，消息内的换行替换为空格
+		for i := n; i < len(b); i++ {
+			if b[i] == 0x0a {
+				b[i] = 0x20
+			}
+		}
+	}
+	return b
+}
+
+// appendSyslogField RFC 5424 头部字段: 可打印 ASCII，不含空格，为空时为 "-"
+func appendSyslogField(b []byte, s string, max int) []byte {
+	n := len(b)
+	for i := 0; i < len(s) && len(b)-n < max; i++ {
+		if s[i] > 0x20 && s[i] < 0x7f {
+			b = append(b, s[i])
+		}
+	}
+	if len(b) == n {
+		b = append(b, '-')
+	}
+	return b
+}
+
+func (s *syslogHandler) octet() bool {
+	return s.config.Network == "tcp" || s.config.Network == "tcp4" || s.config.Network == "tcp6"
+}
+
+// send 发送 s.buf，失败时重连并重试一次
+func (s *syslogHandler) send() (err error) {
+	s.frame = s.frame[:0]
+	if s.octet() {
+		s.frame = strconv.AppendInt(s.frame, int64(len(s.buf)), 10)
+		s.frame = append(s.frame, 0x20)
+	}
+	s.frame = append(s.frame, s.buf...)
+	if s.stream && !s.octet() {
+		s.frame = append(s.frame, 0x0a)
+	}
+	for retry := 0; retry < 2; retry++ {
+		if s.conn != nil && s.stream && !connAlive(s.conn) {
+			_ = s.conn.Close()
+			s.conn = nil
+		}
+		if s.conn == nil {
+			if err = s.connect(); err != nil {
+				continue
+			}
+		}
+		_ = s.conn.SetWriteDeadline(time.Now().Add(s.config.Timeout))
+		if _, err = s.conn.Write(s.frame); err == nil {
+			return nil
+		}
+		_ = s.conn.Close()
+		s.conn = nil
+	}
+	return err
+}
+
+func (s *syslogHandler) connect() (err error) {
+	network, address := s.config.Network, s.config.Address
+	if network != "" && network != "unix" && network != "unixgram" {
+		s.conn, err = net.DialTimeout(network, address, s.config.Timeout)
+		s.stream = s.conn != nil && network != "udp" && network != "udp4" && network != "udp6"
+		return err
+	}
+	paths := syslogLocalPaths
+	if address != "" {
+		paths = []string{address}
+	}
+	networks := []string{"unixgram", "unix"}
+	if network != "" {
+		networks = []string{network}
+	}
+	for _, path := range paths {
+		for _, n := range networks {
+			if s.conn, err = net.DialTimeout(n, path, s.config.Timeout); err == nil {
+				s.stream = n == "unix"
+				return nil
+			}
+		}
+	}
+	if err == nil {
+		err = errors.New("syslog: no local socket")
+	}
+	return err
+}
+
+// NewSyslogHandler 创建 syslog handler，连接失败时返回错误
+func NewSyslogHandler(config SyslogConfig) (IHandler, error) {
+	if config.Facility == SYSLOG_FACILITY_KERN {
+		config.Facility = SYSLOG_FACILITY_USER
+	}
+	if config.AppName == "" {
+		config.AppName = filepath.Base(os.Args[0])
+	}
+	if config.Hostname == "" {
+		config.Hostname = getHostname()
+	}
+	if config.ProcID == "" {
+		config.ProcID = processID
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Second
+	}
+	s := &syslogHandler{config: config}
+	if err := s.connect(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// NewSyslogLogger 创建输出到 syslog 的 Logger
+//
+// 时间及级别由 syslog 头部表示，默认的行格式为 "{caller} {msg}{fields}" 且不输出颜色，可通过 opts 修改
+func NewSyslogLogger(config SyslogConfig, opts ...Option) (*Logger, error) {
+	handler, err := NewSyslogHandler(config)
+	if err != nil {
+		return nil, err
+	}
+	opts = append([]Option{WithLineFormat("{caller} {msg}{fields}"), WithColorMode(FLAG_COLOR_NEVER)}, opts...)
+	return New(handler, opts...), nil
+}
@@ -0,0 +1,148 @@
+package log
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var syslogTestTime = time.Date(2026, 10, 19, 15, 4, 5, 123456000, time.UTC)
+
+func syslogTestConfig(network, address string) SyslogConfig {
+	return SyslogConfig{
+		Network:  network,
+		Address:  address,
+		Facility: SYSLOG_FACILITY_LOCAL0,
+		AppName:  "app",
+		Hostname: "host",
+		ProcID:   "42",
+		Timeout:  time.Second,
+	}
+}
+
+func readPacket(t *testing.T, conn net.PacketConn) string {
+	t.Helper()
+	buf := make([]byte, 4096)
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(buf[:n])
+}
+
+func TestSyslogUnixgramRFC5424(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.sock")
+	conn, err := net.ListenPacket("unixgram", path)
+	if err != nil {
+		t.Skip(err)
+	}
+	defer conn.Close()
+
+	handler, err := NewSyslogHandler(syslogTestConfig("unixgram", path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer handler.Close()
+	record := Record{Level: LV_ERROR, Time: syslogTestTime, Name: "db", Line: []byte("\x1b[31mquery failed\x1b[0m\n")}
+	if err = handler.(RecordHandler).Handle(record); err != nil {
+		t.Fatal(err)
+	}
+	// LOCAL0(16) * 8 + err(3)
+	want := "<131>1 2026-10-19T15:04:05.123456Z host app 42 db - query failed"
+	if got := readPacket(t, conn); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	// 没有名称时 MSGID 为 "-"，Write 以 notice 发送
+	if _, err = handler.Write([]byte("hello\n")); err != nil {
+		t.Fatal(err)
+	}
+	got := readPacket(t, conn)
+	if prefix := "<133>1 "; !strings.HasPrefix(got, prefix) {
+		t.Fatalf("got %q, want prefix %q", got, prefix)
+	}
+	if suffix := " host app 42 - - hello"; !strings.HasSuffix(got, suffix) {
+		t.Fatalf("got %q, want suffix %q", got, suffix)
+	}
+}
+
+func TestSyslogUDPRFC3164(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer conn.Close()
+
+	config := syslogTestConfig("udp", conn.LocalAddr().String())
+	config.Format = FLAG_SYSLOG_RFC3164
+	handler, err := NewSyslogHandler(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer handler.Close()
+	record := Record{Level: LV_WARN, Time: syslogTestTime, Line: []byte("disk full\n")}
+	if err = handler.(RecordHandler).Handle(record); err != nil {
+		t.Fatal(err)
+	}
+	want := "<132>Oct 19 15:04:05 host app[42]: disk full"
+	if got := readPacket(t, conn); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestSyslogTCPOctetCounting(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer listener.Close()
+
+	handler, err := NewSyslogHandler(syslogTestConfig("tcp", listener.Addr().String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer handler.Close()
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	lines := []string{"first line\n", "multi\nline\n"}
+	for _, line := range lines {
+		record := Record{Level: LV_INFO, Time: syslogTestTime, Line: []byte(line)}
+		if err = handler.(RecordHandler).Handle(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	r := bufio.NewReader(conn)
+	want := []string{
+		"<134>1 2026-10-19T15:04:05.123456Z host app 42 - - first line",
+		"<134>1 2026-10-19T15:04:05.123456Z host app 42 - - multi\nline",
+	}
+	for _, msg := range want {
+		head, err := r.ReadString(0x20)
+		if err != nil {
+			t.Fatal(err)
+		}
+		size, err := strconv.Atoi(head[:len(head)-1])
+		if err != nil {
+			t.Fatalf("bad frame length %q", head)
+		}
+		frame := make([]byte, size)
+		if _, err = io.ReadFull(r, frame); err != nil {
+			t.Fatal(err)
+		}
+		if string(frame) != msg {
+			t.Fatalf("got %q, want %q", frame, msg)
+		}
+	}
+}
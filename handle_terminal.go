@@ -12,6 +12,18 @@ func (t *terminalHandler) Write(b []byte) (n int, err error) {
 	return t.w.Write(b)
 }
 
+// Handle 写入 r.Line
+func (t *terminalHandler) Handle(r Record) error {
+	_, err := t.Write(r.Line)
+	return err
+}
+
+func (t *terminalHandler) Enabled(level int) bool {
+	return true
+}
+
+func (t *terminalHandler) lineOnly() {}
+
 func (t *terminalHandler) Close() (err error) {
 	return t.w.Close()
 }
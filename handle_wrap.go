@@ -0,0 +1,33 @@
+package log
+
+// IUnwrapper 包装其他 handler 的 handler，用于查找被包装的 handler(颜色检测、缓冲写入等)
+type IUnwrapper interface {
+	Unwrap() IHandler
+}
+
+// findHandler 沿着包装链查找第一个 T 类型的 handler
+func findHandler[T any](handler IHandler) (T, bool) {
+	for handler != nil {
+		if h, ok := handler.(T); ok {
+			return h, true
+		}
+		u, ok := handler.(IUnwrapper)
+		if !ok {
+			break
+		}
+		handler = u.Unwrap()
+	}
+	var zero T
+	return zero, false
+}
+
+// baseHandler 获取包装链最内层的 handler
+func baseHandler(handler IHandler) IHandler {
+	for {
+		u, ok := handler.(IUnwrapper)
+		if !ok {
+			return handler
+		}
+		handler = u.Unwrap()
+	}
+}
@@ -1,43 +1,53 @@
 package log
 
 import (
+	"os"
 	"path/filepath"
 	"runtime"
+	"runtime/debug"
 	"strings"
+	"sync"
 )
 
 var packagePrefix = getPackagePrefix()
 
+var mainModulePath = getMainModulePath()
+
+// 目录 => 模块根目录
+var moduleRoots sync.Map
+
 func getPackagePrefix() string {
 	var pcs [1]uintptr
 	runtime.Callers(1, pcs[:])
 	frames := runtime.CallersFrames(pcs[:])
 	frame, _ := frames.Next()
 	name := frame.Function
+	// 包含结尾的 "."，避免匹配到同名前缀的包(如 xxx/v2_test)
 	if idx := strings.LastIndex(name, "."); idx > -1 {
-		return name[:idx]
+		return name[:idx+1]
 	}
 	return name
 }
 
+func getMainModulePath() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	return info.Main.Path
+}
+
 func WhoCalledMe() (file string, line int, fn string) {
-	var pc uintptr
-	var ok bool
-	for skip := 1; ; skip++ {
-		pc, file, line, ok = runtime.Caller(skip)
-		if !ok {
-			break
-		}
-		fnc := runtime.FuncForPC(pc)
-		if fnc == nil {
-			continue
+	var pcs [32]uintptr
+	n := runtime.Callers(2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" && !strings.HasPrefix(frame.Function, packagePrefix) {
+			return frame.File, frame.Line, shortFuncName(frame.Function)
 		}
-		fn = fnc.Name()
-		if !strings.HasPrefix(fn, packagePrefix) {
-			if idx := strings.LastIndex(fn, "."); idx > -1 {
-				fn = fn[idx+1:]
-			}
-			return
+		if !more {
+			break
 		}
 	}
 	return "?", 0, "?"
@@ -51,6 +61,71 @@ func ShortFileName(path string) string {
 	return path
 }
 
+// FormatFileName 按照 mode 格式化调用位置的文件路径
+func FormatFileName(path string, mode FLAG_CALLER) string {
+	switch mode {
+	case FLAG_CALLER_PACKAGE:
+		return packageFileName(path)
+	case FLAG_CALLER_MODULE:
+		return moduleFileName(path)
+	case FLAG_CALLER_FULL:
+		return path
+	default:
+		return ShortFileName(path)
+	}
+}
+
+func packageFileName(path string) string {
+	idx := strings.LastIndex(path, "/")
+	if idx < 1 {
+		return path
+	}
+	if idx2 := strings.LastIndex(path[:idx], "/"); idx2 > -1 {
+		return path[idx2+1:]
+	}
+	return path
+}
+
+// moduleFileName 获取相对模块根目录的路径
+//
+// 优先匹配主模块路径(-trimpath 或 GOPATH 布局)，其次向上查找 go.mod，都失败时退化为包目录/文件名
+func moduleFileName(path string) string {
+	if mainModulePath != "" && mainModulePath != "command-line-arguments" {
+		if strings.HasPrefix(path, mainModulePath+"/") {
+			return path[len(mainModulePath)+1:]
+		}
+		if idx := strings.Index(path, "/"+mainModulePath+"/"); idx > -1 {
+			return path[idx+len(mainModulePath)+2:]
+		}
+	}
+	idx := strings.LastIndex(path, "/")
+	if idx < 1 {
+		return path
+	}
+	if root := findModuleRoot(path[:idx]); root != "" {
+		return strings.TrimPrefix(path, root+"/")
+	}
+	return packageFileName(path)
+}
+
+func findModuleRoot(dir string) string {
+	if root, ok := moduleRoots.Load(dir); ok {
+		return root.(string)
+	}
+	root := ""
+	for d := dir; d != "" && d != "/" && d != "."; d = filepath.Dir(d) {
+		if stat, err := os.Stat(d + "/go.mod"); err == nil && !stat.IsDir() {
+			root = filepath.ToSlash(d)
+			break
+		}
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	moduleRoots.Store(dir, root)
+	return root
+}
+
 // GetDirAndFileName 获取目录和文件名
 //
 // dir 目录，尾部带"/"
@@ -2,25 +2,34 @@ package log
 
 import (
 	"os"
+	"sync/atomic"
 )
 
 func New(handler IHandler, opts ...Option) *Logger {
 	logger := &Logger{
-		handler:     handler,
-		enableColor: true,
-		shortName:   false,
-		flagTime:    FLAG_TIME_DATETIME,
-		level:       LV_DEBUG,
-		pool:        poolNew(),
+		handler:   handler,
+		colorMode: FLAG_COLOR_AUTO,
+		theme:     ThemeDark,
+		stripANSI: true,
+		shortName: false,
+		flagTime:  FLAG_TIME_DATETIME,
+		level:     LV_DEBUG,
+		callerLevels: map[int]bool{
+			LV_DEBUG: true,
+			LV_ERROR: true,
+			LV_PANIC: true,
+			LV_FATAL: true,
+		},
+		callerPath: FLAG_CALLER_BASE,
+		layout:     defaultLayout,
+		timeCache:  &atomic.Pointer[timeCache]{},
+		limits:     newLimiter(defaultLimitKeys),
+		pool:       poolNew(),
 	}
+	logger.resolveColor()
 	for _, opt := range opts {
 		opt(logger)
 	}
-
-	switch handler.(type) {
-	case *fileHandler, *fileRotateHandler:
-		logger.enableColor = false
-	}
 	return logger
 }
 
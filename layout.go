@@ -0,0 +1,132 @@
+package log
+
+import (
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DEFAULT_LINE_FORMAT 默认的行格式
+//
+// 支持的占位符:
+//
+//	{time} 时间  {level} 级别  {caller} 文件:行号 函数  {file} 文件:行号  {func} 函数
+//	{msg} 内容  {fields} 字段  {pid} 进程ID  {hostname} 主机名  {goid} 协程ID  {name} Logger名称
+//
+// 占位符内容为空且位于行首或空格之后时，会吞掉其后紧跟的一个空格
+const DEFAULT_LINE_FORMAT = "{time} {level} {caller} {msg}{fields}"
+
+type layoutToken int
+
+const (
+	tokenLiteral layoutToken = iota
+	tokenTime
+	tokenLevel
+	tokenCaller
+	tokenFile
+	tokenFunc
+	tokenMsg
+	tokenFields
+	tokenPid
+	tokenHostname
+	tokenGoid
+	tokenName
+)
+
+var layoutTokens = map[string]layoutToken{
+	"time":     tokenTime,
+	"level":    tokenLevel,
+	"caller":   tokenCaller,
+	"file":     tokenFile,
+	"func":     tokenFunc,
+	"msg":      tokenMsg,
+	"fields":   tokenFields,
+	"pid":      tokenPid,
+	"hostname": tokenHostname,
+	"goid":     tokenGoid,
+	"name":     tokenName,
+}
+
+type layoutSegment struct {
+	token   layoutToken
+	literal string
+}
+
+type layout struct {
+	segments  []layoutSegment
+	hasCaller bool
+}
+
+var defaultLayout = compileLayout(DEFAULT_LINE_FORMAT)
+
+// compileLayout 将行格式编译为片段列表，未知的占位符按原样输出
+func compileLayout(format string) *layout {
+	lt := &layout{}
+	var literal strings.Builder
+	for len(format) > 0 {
+		start := strings.IndexByte(format, '{')
+		if start < 0 {
+			literal.WriteString(format)
+			break
+		}
+		end := strings.IndexByte(format[start:], '}')
+		if end < 0 {
+			literal.WriteString(format)
+			break
+		}
+		end += start
+		token, ok := layoutTokens[format[start+1:end]]
+		if !ok {
+			literal.WriteString(format[:end+1])
+			format = format[end+1:]
+			continue
+		}
+		literal.WriteString(format[:start])
+		if literal.Len() > 0 {
+			lt.segments = append(lt.segments, layoutSegment{token: tokenLiteral, literal: literal.String()})
+			literal.Reset()
+		}
+		lt.segments = append(lt.segments, layoutSegment{token: token})
+		if token == tokenCaller || token == tokenFile || token == tokenFunc {
+			lt.hasCaller = true
+		}
+		format = format[end+1:]
+	}
+	if literal.Len() > 0 {
+		lt.segments = append(lt.segments, layoutSegment{token: tokenLiteral, literal: literal.String()})
+	}
+	return lt
+}
+
+var (
+	processID    = strconv.Itoa(os.Getpid())
+	hostnameOnce sync.Once
+	hostname     string
+)
+
+func getHostname() string {
+	hostnameOnce.Do(func() {
+		hostname, _ = os.Hostname()
+	})
+	return hostname
+}
+
+func goroutineID() uint64 {
+	var stack [64]byte
+	n := runtime.Stack(stack[:], false)
+	// goroutine 123 [running]:
+	b := stack[:n]
+	if len(b) > 10 {
+		b = b[10:]
+	}
+	var id uint64
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			break
+		}
+		id = id*10 + uint64(c-'0')
+	}
+	return id
+}
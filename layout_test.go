@@ -0,0 +1,14 @@
+package log
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEmptyLineFormat(t *testing.T) {
+	logger, h := newTestLogger(WithLineFormat(""), WithTimeStyle(FLAG_TIME_NONE))
+	logger.Info("hello")
+	if got := h.String(); !strings.HasPrefix(got, "INF ") || !strings.HasSuffix(got, " hello\n") {
+		t.Fatalf("got %q, want the default line format", got)
+	}
+}
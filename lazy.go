@@ -0,0 +1,61 @@
+package log
+
+// LazyValue 延迟求值的参数，只有日志真正输出时才会调用
+//
+// eg: logger.Debug("users:", log.LazyValue(func() any { return loadUsers() }))
+type LazyValue func() any
+
+// Enabled 指定级别的日志是否会输出，用于跳过昂贵的参数计算
+func (l *Logger) Enabled(level int) bool {
+	if level < l.level {
+		return false
+	}
+	record, ok := l.handler.(RecordHandler)
+	return !ok || record.Enabled(level)
+}
+
+func resolveLazy(args []any) []any {
+	var result []any
+	for i, arg := range args {
+		lazy, ok := arg.(LazyValue)
+		if !ok {
+			continue
+		}
+		if result == nil {
+			result = make([]any, len(args))
+			copy(result, args)
+		}
+		result[i] = lazy()
+	}
+	if result == nil {
+		return args
+	}
+	return result
+}
+
+func (l *Logger) logFn(lv int, fn func() string) {
+	if !l.Enabled(lv) {
+		return
+	}
+	l.output(lv, false, "", false, []any{fn()})
+}
+
+func (l *Logger) PrintFn(fn func() string) {
+	l.logFn(LV_PRINT, fn)
+}
+
+func (l *Logger) InfoFn(fn func() string) {
+	l.logFn(LV_INFO, fn)
+}
+
+func (l *Logger) WarnFn(fn func() string) {
+	l.logFn(LV_WARN, fn)
+}
+
+func (l *Logger) ErrorFn(fn func() string) {
+	l.logFn(LV_ERROR, fn)
+}
+
+func (l *Logger) DebugFn(fn func() string) {
+	l.logFn(LV_DEBUG, fn)
+}
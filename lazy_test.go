@@ -0,0 +1,39 @@
+package log
+
+import "testing"
+
+// recordingHandler 保存收到的 Record 及其字段
+type recordingHandler struct {
+	bufferHandler
+	fields []Field
+}
+
+func (h *recordingHandler) Handle(r Record) error {
+	h.fields = append([]Field(nil), r.Fields...)
+	_, err := h.Write(r.Line)
+	return err
+}
+
+func (h *recordingHandler) Enabled(level int) bool {
+	return true
+}
+
+func TestLazyFieldResolvedOnce(t *testing.T) {
+	h := &recordingHandler{}
+	logger := New(h, WithColorMode(FLAG_COLOR_NEVER), WithLineFormat("{msg}{fields}"))
+	calls := 0
+	logger.With("n", LazyValue(func() any {
+		calls++
+		return calls
+	}), "s", LazyValue(func() any { return "\x1b[31mred\x1b[0m" })).Info("done")
+
+	if calls != 1 {
+		t.Fatalf("lazy field called %d times, want 1", calls)
+	}
+	if got, want := h.String(), "done n=1 s=red\n"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if len(h.fields) != 2 || h.fields[0].Value != 1 || h.fields[1].Value != "red" {
+		t.Fatalf("unexpected record fields %v", h.fields)
+	}
+}
@@ -0,0 +1,176 @@
+package log
+
+import (
+	"container/list"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// 默认最多记录的限流 key 数量
+const defaultLimitKeys = 1024
+
+// limitKey 同一行的调用位置 pc 可能相同，同时以限流参数区分
+type limitKey struct {
+	pc     uintptr
+	key    string
+	every  time.Duration
+	firstN int
+}
+
+type limitEntry struct {
+	key   limitKey
+	last  time.Time
+	count int
+}
+
+// limiter Every、FirstN、Once 的状态，超过 max 个 key 时淘汰最久未使用的
+type limiter struct {
+	lock    sync.Mutex
+	max     int
+	entries map[limitKey]*list.Element
+	lru     *list.List
+}
+
+func newLimiter(max int) *limiter {
+	if max < 1 {
+		max = defaultLimitKeys
+	}
+	return &limiter{
+		max:     max,
+		entries: make(map[limitKey]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// allow 判断 key 是否放行并更新状态，every > 0 时按时间间隔，否则按次数(最多 firstN 次)
+func (m *limiter) allow(key limitKey) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	var entry *limitEntry
+	if elem, ok := m.entries[key]; ok {
+		m.lru.MoveToFront(elem)
+		entry = elem.Value.(*limitEntry)
+	} else {
+		if m.lru.Len() >= m.max {
+			oldest := m.lru.Back()
+			m.lru.Remove(oldest)
+			delete(m.entries, oldest.Value.(*limitEntry).key)
+		}
+		entry = &limitEntry{key: key}
+		m.entries[key] = m.lru.PushFront(entry)
+	}
+	if key.every > 0 {
+		now := time.Now()
+		if entry.count > 0 && now.Sub(entry.last) < key.every {
+			return false
+		}
+		entry.last = now
+		entry.count++
+		return true
+	}
+	if entry.count >= key.firstN {
+		return false
+	}
+	entry.count++
+	return true
+}
+
+// LimitedLogger 限流的 Logger，见 Logger.Every、Logger.FirstN、Logger.Once
+type LimitedLogger struct {
+	logger *Logger
+	key    limitKey
+	// 不限流，见 Every
+	always bool
+}
+
+// callSite 调用 Every、FirstN、Once 的位置(按行区分)
+func callSite() uintptr {
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+	return pcs[0]
+}
+
+// Every 同一调用位置每隔 d 最多输出一次，d <= 0 时每次都输出
+//
+// eg: logger.Every(time.Minute).Warn("queue is full")
+func (l *Logger) Every(d time.Duration) LimitedLogger {
+	if d <= 0 {
+		return LimitedLogger{logger: l, always: true}
+	}
+	return LimitedLogger{logger: l, key: limitKey{pc: callSite(), every: d}}
+}
+
+// FirstN 同一调用位置只输出前 n 次
+func (l *Logger) FirstN(n int) LimitedLogger {
+	return LimitedLogger{logger: l, key: limitKey{pc: callSite(), firstN: n}}
+}
+
+// Once 同一 key 只输出一次，key 为空时按调用位置
+func (l *Logger) Once(key string) LimitedLogger {
+	limit := LimitedLogger{logger: l, key: limitKey{key: key, firstN: 1}}
+	if key == "" {
+		limit.key.pc = callSite()
+	}
+	return limit
+}
+
+// allow 级别未开启时不计数
+func (g LimitedLogger) allow(lv int) bool {
+	return g.logger.Enabled(lv) && (g.always || g.logger.limits.allow(g.key))
+}
+
+func (g LimitedLogger) Print(args ...any) {
+	if g.allow(LV_PRINT) {
+		g.logger.log(LV_PRINT, args...)
+	}
+}
+func (g LimitedLogger) Printf(format string, args ...any) {
+	if g.allow(LV_PRINT) {
+		g.logger.logf(LV_PRINT, format, args...)
+	}
+}
+
+func (g LimitedLogger) Info(args ...any) {
+	if g.allow(LV_INFO) {
+		g.logger.log(LV_INFO, args...)
+	}
+}
+func (g LimitedLogger) Infof(format string, args ...any) {
+	if g.allow(LV_INFO) {
+		g.logger.logf(LV_INFO, format, args...)
+	}
+}
+
+func (g LimitedLogger) Warn(args ...any) {
+	if g.allow(LV_WARN) {
+		g.logger.log(LV_WARN, args...)
+	}
+}
+func (g LimitedLogger) Warnf(format string, args ...any) {
+	if g.allow(LV_WARN) {
+		g.logger.logf(LV_WARN, format, args...)
+	}
+}
+
+func (g LimitedLogger) Error(args ...any) {
+	if g.allow(LV_ERROR) {
+		g.logger.log(LV_ERROR, args...)
+	}
+}
+func (g LimitedLogger) Errorf(format string, args ...any) {
+	if g.allow(LV_ERROR) {
+		g.logger.logf(LV_ERROR, format, args...)
+	}
+}
+
+func (g LimitedLogger) Debug(args ...any) {
+	if g.allow(LV_DEBUG) {
+		g.logger.log(LV_DEBUG, args...)
+	}
+}
+func (g LimitedLogger) Debugf(format string, args ...any) {
+	if g.allow(LV_DEBUG) {
+		g.logger.logf(LV_DEBUG, format, args...)
+	}
+}
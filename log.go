@@ -3,24 +3,68 @@ package log
 import (
 	"fmt"
 	"os"
-	"reflect"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type Logger struct {
 	handler     IHandler
 	enableColor bool
+	colorMode   FLAG_COLOR
+	theme       *Theme
+	stripANSI   bool
 	shortName   bool
 	flagTime    FLAG_TIME
 	level       int
 	pool        *sync.Pool
+	// 自定义时间格式及时区
+	timeLayout   string
+	timeLocation *time.Location
+	timeCache    *atomic.Pointer[timeCache]
+	// 需要输出调用位置的级别
+	callerLevels map[int]bool
+	callerPath   FLAG_CALLER
+	callerSkip   int
+	// 需要附加调用栈的级别
+	stackLevels map[int]bool
+	stackDepth  int
+	// 展开错误链
+	errorChain bool
+	// 需要美化输出的级别
+	prettyLevels map[int]bool
+	prettyDepth  int
+	prettyLen    int
+	// 多行内容的处理方式
+	multiline       FLAG_MULTILINE
+	multilineMarker string
+	// 内容及字段的最大字节数
+	maxMessageBytes int
+	maxFieldBytes   int
+	redactor        *Redactor
+	// 缓冲写入时，不低于该级别的日志立即刷新
+	buffered   bool
+	flushLevel int
+	// Every、FirstN、Once 的状态
+	limits *limiter
+	// 行格式
+	layout *layout
+	name   string
+	fields []Field
 }
 
 type writePool struct {
 	buffer []byte
+	// 去除颜色的消息内容，见 Record.Message
+	message []byte
+	// 见 Record.TimeText、Record.Fields、Record.ErrorChain
+	time   []byte
+	fields []Field
+	chain  []string
+	// 设置了 WithMaxMessageBytes 时用于渲染消息
+	bounded boundedWriter
 }
 
 func poolNew() *sync.Pool {
@@ -33,191 +77,494 @@ func poolNew() *sync.Pool {
 	}
 }
 
-func (l *Logger) withPrefix(lv int, buf *writePool, skikCaller bool) {
-	now := time.Now()
+// 进程启动时间，用于 FLAG_TIME_ELAPSED
+var processStart = time.Now()
+
+// appendTime 按照 flagTime 追加时间，不含尾部空格
+func (l *Logger) appendTime(b []byte, now time.Time) []byte {
+	if l.flagTime == FLAG_TIME_ELAPSED {
+		// In 会去掉单调时钟，需在转换时区前计算
+		b = strconv.AppendFloat(b, now.Sub(processStart).Seconds(), 'f', 6, 64)
+		return append(b, 's')
+	}
+	if l.timeLocation != nil {
+		now = now.In(l.timeLocation)
+	}
 	switch l.flagTime {
-	case FLAG_TIME_DATE:
-		buf.buffer = append(buf.buffer, now.Format("2006/01/02 ")...)
-	case FLAG_TIME_TIME:
-		buf.buffer = append(buf.buffer, now.Format("15:04:05.000 ")...)
-	case FLAG_TIME_DATETIME:
-		buf.buffer = append(buf.buffer, now.Format("2006/01/02 15:04:05.000 ")...)
+	case FLAG_TIME_DATE, FLAG_TIME_TIME, FLAG_TIME_DATETIME:
+		return l.appendCachedTime(b, now)
 	case FLAG_TIME_TIMESTAMP:
-		buf.buffer = append(buf.buffer, fmt.Appendf(nil, "%d ", now.UnixMilli())...)
-	case FLAG_TIME_NONE:
-		// none time
+		return strconv.AppendInt(b, now.UnixMilli(), 10)
+	case FLAG_TIME_RFC3339NANO:
+		return now.AppendFormat(b, time.RFC3339Nano)
+	case FLAG_TIME_TIMESTAMP_MICRO:
+		return strconv.AppendInt(b, now.UnixMicro(), 10)
+	case FLAG_TIME_TIMESTAMP_NANO:
+		return strconv.AppendInt(b, now.UnixNano(), 10)
+	case FLAG_TIME_LAYOUT:
+		return now.AppendFormat(b, l.timeLayout)
+	}
+	return b
+}
+
+// timeCache 缓存格式化到秒的时间，同一秒内只需追加毫秒
+type timeCache struct {
+	sec    int64
+	flag   FLAG_TIME
+	loc    *time.Location
+	prefix []byte
+}
+
+func (l *Logger) appendCachedTime(b []byte, now time.Time) []byte {
+	sec := now.Unix()
+	c := l.timeCache.Load()
+	if c == nil || c.sec != sec || c.flag != l.flagTime || c.loc != l.timeLocation {
+		layout := "2006/01/02 15:04:05"
+		switch l.flagTime {
+		case FLAG_TIME_DATE:
+			layout = "2006/01/02"
+		case FLAG_TIME_TIME:
+			layout = "15:04:05"
+		}
+		c = &timeCache{sec: sec, flag: l.flagTime, loc: l.timeLocation, prefix: now.AppendFormat(nil, layout)}
+		l.timeCache.Store(c)
+	}
+	b = append(b, c.prefix...)
+	if l.flagTime == FLAG_TIME_DATE {
+		return b
+	}
+	ms := now.Nanosecond() / 1e6
+	return append(b, '.', byte('0'+ms/100), byte('0'+ms/10%10), byte('0'+ms%10))
+}
+
+func (l *Logger) output(lv int, skipCaller bool, format string, hasFormat bool, args []any) {
+	// handler 可能被 UseOption 包装替换，每次从当前的 handler 获取
+	record, _ := l.handler.(RecordHandler)
+	if record != nil && !record.Enabled(lv) {
+		return
 	}
+	buf := l.pool.Get().(*writePool)
+	buf.buffer = buf.buffer[:0]
+	buf.message = buf.message[:0]
+	buf.time = buf.time[:0]
+	defer l.putBuffer(buf)
 
-	attr := LV_ATTRS[lv]
-	flagName := ifs(l.shortName, attr.ShortName, attr.Name)
-	if l.enableColor {
-		buf.buffer = append(buf.buffer, []byte(ColorWrap(flagName, attr.Color...))...)
+	args = resolveLazy(args)
+	if l.redactor != nil {
+		args = l.redactor.hookArgs(args)
+	}
+	// 字段只求值一次，文本输出与 Record 共用
+	if len(l.fields) > 0 {
+		buf.fields = l.resolveFields(buf.fields)
+	}
+
+	var file, fn string
+	var line int
+	withCaller := !skipCaller && l.callerLevels[lv] && l.layout.hasCaller
+	if withCaller {
+		file, line, fn = l.caller()
+		file = FormatFileName(file, l.callerPath)
+	}
+
+	var now time.Time
+	skipSpace := false
+	for _, seg := range l.layout.segments {
+		if seg.token == tokenLiteral {
+			literal := seg.literal
+			if skipSpace && literal[0] == 0x20 {
+				literal = literal[1:]
+			}
+			buf.buffer = append(buf.buffer, literal...)
+			skipSpace = false
+			continue
+		}
+		n := len(buf.buffer)
+		switch seg.token {
+		case tokenTime:
+			if l.flagTime != FLAG_TIME_NONE {
+				now = time.Now()
+				colored := l.enableColor && len(l.theme.Time) > 0
+				if colored {
+					buf.buffer = appendSGR(buf.buffer, l.theme.Time)
+				}
+				start := len(buf.buffer)
+				buf.buffer = l.appendTime(buf.buffer, now)
+				if record != nil {
+					buf.time = append(buf.time[:0], buf.buffer[start:]...)
+				}
+				if colored {
+					buf.buffer = append(buf.buffer, COLOR_CTRL_RESET...)
+				}
+			}
+		case tokenLevel:
+			attr := LV_ATTRS[lv]
+			flagName := ifs(l.shortName, attr.ShortName, attr.Name)
+			if l.enableColor {
+				buf.buffer = l.appendColorValue(buf.buffer, flagName, l.theme.level(lv))
+			} else {
+				buf.buffer = append(buf.buffer, flagName...)
+			}
+		case tokenCaller:
+			if withCaller {
+				buf.buffer = l.appendFile(buf.buffer, file, line)
+				buf.buffer = append(buf.buffer, 0x20)
+				buf.buffer = l.appendFunc(buf.buffer, fn)
+			}
+		case tokenFile:
+			if withCaller {
+				buf.buffer = l.appendFile(buf.buffer, file, line)
+			}
+		case tokenFunc:
+			if withCaller {
+				buf.buffer = l.appendFunc(buf.buffer, fn)
+			}
+		case tokenMsg:
+			l.withMessage(lv, buf, format, hasFormat, args)
+			if record != nil {
+				buf.message = StripANSI(append(buf.message, buf.buffer[n:]...))
+			}
+		case tokenFields:
+			buf.buffer = l.appendFields(buf.buffer, buf.fields)
+		case tokenPid:
+			buf.buffer = append(buf.buffer, processID...)
+		case tokenHostname:
+			buf.buffer = append(buf.buffer, getHostname()...)
+		case tokenGoid:
+			buf.buffer = strconv.AppendUint(buf.buffer, goroutineID(), 10)
+		case tokenName:
+			buf.buffer = append(buf.buffer, l.name...)
+		}
+		// 内容为空且前面已是行首或空格时，吞掉其后的一个空格
+		skipSpace = len(buf.buffer) == n && (n == 0 || buf.buffer[n-1] == 0x20)
+	}
+	if skipSpace && len(buf.buffer) > 0 && buf.buffer[len(buf.buffer)-1] == 0x20 {
+		buf.buffer = buf.buffer[:len(buf.buffer)-1]
+	}
+
+	if !skipCaller {
+		l.withErrorChain(buf, args)
+		if record != nil {
+			l.withErrorTexts(buf, args)
+		}
+		l.withStack(lv, buf, args)
+	}
+	buf.buffer = l.foldLines(buf.buffer)
+	buf.buffer = append(buf.buffer, 0x0a)
+	if l.stripANSI && !l.enableColor {
+		buf.buffer = StripANSI(buf.buffer)
+	}
+	if record != nil {
+		if now.IsZero() {
+			now = time.Now()
+		}
+		if l.timeLocation != nil {
+			now = now.In(l.timeLocation)
+		}
+		l.recordFields(buf.fields)
+		_ = record.Handle(Record{
+			Level:      lv,
+			Time:       now,
+			TimeText:   buf.time,
+			Caller:     Caller{File: file, Line: line, Func: fn},
+			Name:       l.name,
+			Message:    buf.message,
+			Fields:     buf.fields,
+			ErrorChain: buf.chain,
+			Line:       buf.buffer,
+		})
 	} else {
-		buf.buffer = append(buf.buffer, []byte(flagName)...)
+		l.handler.Write(buf.buffer)
+	}
+	if l.buffered && lv >= l.flushLevel {
+		_ = l.Flush()
+	}
+}
+
+// Close 关闭 handler，异步及缓冲的 handler 会先写入尚未输出的日志
+func (l *Logger) Close() error {
+	return l.handler.Close()
+}
+
+// Flush 刷新 handler 的缓冲区，见 WithFileBuffer
+func (l *Logger) Flush() error {
+	if f, ok := findHandler[IFlusher](l.handler); ok {
+		return f.Flush()
 	}
-	buf.buffer = append(buf.buffer, 0x20)
+	return nil
+}
 
-	if !skikCaller && (lv == LV_DEBUG || lv == LV_ERROR || lv == LV_FATAL || lv == LV_PANIC) {
-		file, line, fn := WhoCalledMe()
-		file = ShortFileName(file)
-		if l.enableColor {
-			buf.buffer = append(buf.buffer, fmt.Appendf(nil, "%s%s%s%s:%d %s%s%s ", COLOR_CTRL_RESET, COLOR_FG_YELLOW, COLOR_CTRL_UNDERLINE, file, line, COLOR_FG_RED, fn, COLOR_CTRL_RESET)...)
+func (l *Logger) withMessage(lv int, buf *writePool, format string, hasFormat bool, args []any) {
+	n := len(buf.buffer)
+	dropped := 0
+	// 渲染的上限，设置了脱敏器时多保留 redactMargin 字节，避免跨过截断位置的敏感内容不能匹配规则
+	bound := l.maxMessageBytes
+	if bound > 0 && l.redactor != nil {
+		bound += redactMargin
+	}
+	if bound > 0 {
+		args, dropped = boundArgs(args, bound)
+	}
+	if l.prettyLevels[lv] {
+		args = l.prettyArgs(args)
+	}
+	if bound > 0 && !l.enableColor {
+		// 最多渲染 bound+1 字节，超出的部分在截断标记中计数
+		buf.bounded = boundedWriter{b: buf.buffer, limit: n + bound + 1}
+		if hasFormat {
+			fmt.Fprintf(&buf.bounded, format, args...)
 		} else {
-			buf.buffer = append(buf.buffer, fmt.Appendf(nil, "%s:%d %s ", file, line, fn)...)
+			fmt.Fprint(&buf.bounded, args...)
+		}
+		buf.buffer, dropped = buf.bounded.b, dropped+buf.bounded.dropped
+		buf.bounded.b = nil
+	} else {
+		buf.buffer = l.appendMessage(buf.buffer, format, hasFormat, args)
+	}
+	if l.multiline != FLAG_MULTILINE_NONE {
+		buf.buffer = trimNewline(buf.buffer, n)
+	}
+	if l.redactor != nil {
+		buf.buffer = l.redactor.redactBytes(buf.buffer, n)
+	}
+	buf.buffer = l.truncateBytes(buf.buffer, n, l.maxMessageBytes, dropped)
+}
+
+func (l *Logger) appendFile(b []byte, file string, line int) []byte {
+	colored := l.enableColor && len(l.theme.CallerFile) > 0
+	if colored {
+		b = appendSGR(b, l.theme.CallerFile)
+	}
+	b = append(b, file...)
+	b = append(b, ':')
+	b = strconv.AppendInt(b, int64(line), 10)
+	if colored {
+		b = append(b, COLOR_CTRL_RESET...)
+	}
+	return b
+}
+
+func (l *Logger) appendFunc(b []byte, fn string) []byte {
+	if l.enableColor && len(l.theme.CallerFunc) > 0 {
+		return appendColorize(b, fn, l.theme.CallerFunc)
+	}
+	return append(b, fn...)
+}
+
+func (l *Logger) appendMessage(b []byte, format string, hasFormat bool, args []any) []byte {
+	switch {
+	case hasFormat && l.enableColor:
+		return l.appendColorFormat(b, format, args)
+	case hasFormat:
+		return fmt.Appendf(b, format, args...)
+	case l.enableColor:
+		return l.appendColorArgs(b, args)
+	default:
+		return appendArgs(b, args)
+	}
+}
+
+// appendArgs 同 fmt.Append，单个字符串参数时直接追加
+func appendArgs(b []byte, args []any) []byte {
+	if len(args) == 1 {
+		if str, ok := args[0].(string); ok {
+			return append(b, str...)
 		}
 	}
+	return fmt.Append(b, args...)
 }
 
-func (l *Logger) colorArgs(needSpace bool, args ...any) []any {
-	result := make([]any, 0)
-	for _, arg := range args {
-		result = append(result, l.colorTypes(arg, ""))
-		if needSpace {
-			result = append(result, " ")
+// appendColorArgs 按照参数类型着色，参数之间以空格分隔
+func (l *Logger) appendColorArgs(b []byte, args []any) []byte {
+	for i, arg := range args {
+		if i > 0 {
+			b = append(b, 0x20)
 		}
+		b = l.appendColorType(b, arg, "")
 	}
-	return result
+	return b
 }
 
-func (l *Logger) colorTypes(arg any, verb string) string {
+func (l *Logger) appendColorType(b []byte, arg any, verb string) []byte {
 	if arg == nil {
-		return ColorWrap("nil", COLOR_FG_BLUE)
-	}
-	str := ifs(verb == "", fmt.Sprint(arg), fmt.Sprintf(verb, arg))
-	switch reflect.ValueOf(arg).Kind() {
-	case reflect.String:
-		return str
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
-		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
-		reflect.Float32, reflect.Float64, reflect.Ptr, reflect.Uintptr,
-		reflect.Complex64, reflect.Complex128:
-		return ColorWrap(str, COLOR_FG_MAGENTA)
-	case reflect.Bool, reflect.Array, reflect.Slice, reflect.Map:
-		return ColorWrap(str, COLOR_FG_CYAN)
-	case reflect.Struct, reflect.Chan, reflect.Func, reflect.Interface:
-		return ColorWrap(str, COLOR_FG_BLUE)
-	default:
-		return str
+		return l.appendColorValue(b, "nil", l.theme.Nil)
+	}
+	if pv, ok := arg.(prettyValue); ok {
+		if verb == "" || verb[len(verb)-1] == 'v' {
+			return l.pretty(b, pv.value, true)
+		}
+		arg = pv.value
+	}
+	colors := l.theme.kind(arg)
+	if len(colors) > 0 {
+		b = appendSGR(b, colors)
 	}
+	if verb == "" {
+		b = fmt.Append(b, arg)
+	} else {
+		b = fmt.Appendf(b, verb, arg)
+	}
+	if len(colors) > 0 {
+		b = append(b, COLOR_CTRL_RESET...)
+	}
+	return b
 }
 
-func (l *Logger) colorFormatArgs(format string, args ...any) string {
-	matches := REG_PLACEHOLDER.FindAllStringSubmatchIndex(format, -1)
-	var sb strings.Builder
+func (l *Logger) appendColorValue(b []byte, str string, colors []COLOR_ENUM) []byte {
+	if len(colors) == 0 {
+		return append(b, str...)
+	}
+	return appendColorize(b, str, colors)
+}
+
+// appendColorFormat 逐个格式化动词着色，规则同 REG_PLACEHOLDER
+func (l *Logger) appendColorFormat(b []byte, format string, args []any) []byte {
 	var lastIndex, argIndex int = 0, 0
-	for _, match := range matches {
-		start, end := match[0], match[1]
+	for {
+		start, end, idx, ok := nextVerb(format, lastIndex)
+		if !ok {
+			break
+		}
 		verb := format[start:end]
-		sb.WriteString(format[lastIndex:start])
+		b = append(b, format[lastIndex:start]...)
+		lastIndex = end
 		if verb == "%%" {
-			sb.WriteString("%")
-			lastIndex = end
+			b = append(b, '%')
 			continue
 		}
 
-		var idx int
-		hasIndex := false
-		m2, m3 := match[2], match[3]
-		if m2 > 0 && m3 > 0 {
-			idxStr := format[m2+1 : m3-1]
-			idx, _ = strconv.Atoi(idxStr)
-			hasIndex = true
-		}
 		var arg any
-		if hasIndex {
-			if idx < 1 || idx > len(args) {
-				arg = nil
-			} else {
+		if idx > 0 {
+			// 同 fmt，显式索引之后的动词从下一个参数继续
+			argIndex = idx
+			if idx <= len(args) {
 				arg = args[idx-1]
 			}
-		} else {
-			if argIndex >= len(args) {
-				arg = nil
-			} else {
-				arg = args[argIndex]
-				argIndex++
+		} else if argIndex < len(args) {
+			arg = args[argIndex]
+			argIndex++
+		}
+		b = l.appendColorType(b, arg, verb)
+	}
+	return append(b, format[lastIndex:]...)
+}
+
+// nextVerb 从 from 开始查找下一个格式化动词 [start, end)，idx 为显式参数索引 %[n]d，没有时为 0
+//
+// 语法同 REG_PLACEHOLDER: %(\[[1-9]\d*\])?([+\-# 0]*)(\d+|\*)?(\.(\d+|\*))?([a-zA-Z%])
+func nextVerb(format string, from int) (start, end, idx int, ok bool) {
+	for start = from; start < len(format); start++ {
+		if format[start] != '%' {
+			continue
+		}
+		if end, idx, ok = matchVerb(format, start+1); ok {
+			return
+		}
+	}
+	return 0, 0, 0, false
+}
+
+func matchVerb(format string, i int) (end, idx int, ok bool) {
+	n := len(format)
+	// [n]
+	if i+2 < n && format[i] == '[' && format[i+1] >= '1' && format[i+1] <= '9' {
+		j := i + 1
+		num := 0
+		for j < n && isDigit(format[j]) {
+			num = num*10 + int(format[j]-'0')
+			j++
+		}
+		if j < n && format[j] == ']' {
+			idx = num
+			i = j + 1
+		}
+	}
+	// flags
+	for i < n && strings.IndexByte("+-# 0", format[i]) > -1 {
+		i++
+	}
+	// width
+	if i < n && format[i] == '*' {
+		i++
+	} else {
+		for i < n && isDigit(format[i]) {
+			i++
+		}
+	}
+	// precision
+	if i+1 < n && format[i] == '.' {
+		if format[i+1] == '*' {
+			i += 2
+		} else if isDigit(format[i+1]) {
+			i += 2
+			for i < n && isDigit(format[i]) {
+				i++
 			}
 		}
-		sb.WriteString(l.colorTypes(arg, verb))
-		lastIndex = end
 	}
-	sb.WriteString(format[lastIndex:])
-	return sb.String()
+	if i < n && (isLetter(format[i]) || format[i] == '%') {
+		return i + 1, idx, true
+	}
+	return 0, 0, false
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
 }
 
 func (l *Logger) log(lv int, args ...any) {
 	if lv < l.level {
 		return
 	}
-	buf := l.pool.Get().(*writePool)
-	buf.buffer = buf.buffer[:0]
-	defer l.pool.Put(buf)
-	l.withPrefix(lv, buf, false)
-	if l.enableColor {
-		args = l.colorArgs(true, args...)
-	}
-	buf.buffer = append(buf.buffer, fmt.Sprint(args...)...)
-	buf.buffer = append(buf.buffer, 0x0a)
-	l.handler.Write(buf.buffer)
+	l.output(lv, false, "", false, args)
 }
 
 func (l *Logger) logf(lv int, format string, args ...any) {
 	if lv < l.level {
 		return
 	}
-	buf := l.pool.Get().(*writePool)
-	buf.buffer = buf.buffer[:0]
-	defer l.pool.Put(buf)
-	l.withPrefix(lv, buf, false)
-	if l.enableColor {
-		buf.buffer = append(buf.buffer, l.colorFormatArgs(format, args...)...)
-	} else {
-		buf.buffer = append(buf.buffer, fmt.Sprintf(format, args...)...)
-	}
-	buf.buffer = append(buf.buffer, 0x0a)
-	l.handler.Write(buf.buffer)
+	l.output(lv, false, format, true, args)
 }
 
 func (l *Logger) logf_gorm(lv int, format string, args ...any) {
 	if lv < l.level {
 		return
 	}
-	buf := l.pool.Get().(*writePool)
-	buf.buffer = buf.buffer[:0]
-	defer l.pool.Put(buf)
-	l.withPrefix(lv, buf, true)
-	if l.enableColor {
-		buf.buffer = append(buf.buffer, l.colorFormatArgs(format, args...)...)
-	} else {
-		buf.buffer = append(buf.buffer, fmt.Sprintf(format, args...)...)
-	}
-	buf.buffer = append(buf.buffer, 0x0a)
-	l.handler.Write(buf.buffer)
+	l.output(lv, true, format, true, args)
 }
 
 func (l *Logger) Fatal(args ...any) {
 	l.log(LV_FATAL, args...)
+	_ = l.Flush()
 	os.Exit(1)
 }
 func (l *Logger) Fatalf(format string, args ...any) {
 	l.logf(LV_FATAL, format, args...)
+	_ = l.Flush()
 	os.Exit(1)
 }
 func (l *Logger) Fatalln(args ...any) {
 	l.log(LV_FATAL, args...)
+	_ = l.Flush()
 	os.Exit(1)
 }
 
 func (l *Logger) Panic(args ...any) {
 	l.log(LV_PANIC, args...)
+	_ = l.Flush()
 	panic(fmt.Errorf(fmt.Sprint(args...)))
 }
 func (l *Logger) Panicf(format string, args ...any) {
 	l.logf(LV_PANIC, format, args...)
+	_ = l.Flush()
 	panic(fmt.Errorf(format, args...))
 }
 func (l *Logger) Panicln(args ...any) {
 	l.log(LV_PANIC, args...)
+	_ = l.Flush()
 	panic(fmt.Errorf(fmt.Sprint(args...)))
 }
 
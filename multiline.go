@@ -0,0 +1,54 @@
+package log
+
+import (
+	"bytes"
+)
+
+// 续行默认的标记
+const DEFAULT_MULTILINE_MARKER = "  | "
+
+// foldLines 按照 multiline 模式处理 b 中的换行，保证一条记录只占一行或续行可识别
+func (l *Logger) foldLines(b []byte) []byte {
+	if l.multiline == FLAG_MULTILINE_NONE {
+		return b
+	}
+	chars := "\r\n"
+	if l.multiline == FLAG_MULTILINE_ESCAPE {
+		// 同时转义反斜杠，区分原文中的 `\n` 与换行
+		chars = "\r\n\\"
+	}
+	i := bytes.IndexAny(b, chars)
+	if i < 0 {
+		return b
+	}
+	tail := bytes.TrimRight(b[i:], "\r\n")
+	if len(tail) == 0 {
+		return b[:i]
+	}
+	tail = append([]byte(nil), tail...)
+	b = b[:i]
+	for _, c := range tail {
+		switch {
+		case c == '\\' && l.multiline == FLAG_MULTILINE_ESCAPE:
+			b = append(b, `\\`...)
+		case c == 0x0a && l.multiline == FLAG_MULTILINE_ESCAPE:
+			b = append(b, `\n`...)
+		case c == 0x0d && l.multiline == FLAG_MULTILINE_ESCAPE:
+			b = append(b, `\r`...)
+		case c == 0x0a:
+			b = append(b, 0x0a)
+			b = append(b, l.multilineMarker...)
+		default:
+			b = append(b, c)
+		}
+	}
+	return b
+}
+
+// trimNewline 去掉 b[start:] 尾部的换行
+func trimNewline(b []byte, start int) []byte {
+	for len(b) > start && (b[len(b)-1] == 0x0a || b[len(b)-1] == 0x0d) {
+		b = b[:len(b)-1]
+	}
+	return b
+}
@@ -0,0 +1,25 @@
+package log
+
+import (
+	"testing"
+)
+
+func TestMultilineEscapeBackslash(t *testing.T) {
+	logger, h := newTestLogger(WithMultiline(FLAG_MULTILINE_ESCAPE, ""))
+	logger.Info(`a\nb`)
+	logger.Info("a\nb")
+	logger.Info(`C:\dir`)
+	want := `a\\nb` + "\n" + `a\nb` + "\n" + `C:\\dir` + "\n"
+	if got := h.String(); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestMultilineIndent(t *testing.T) {
+	logger, h := newTestLogger(WithMultiline(FLAG_MULTILINE_INDENT, ""))
+	logger.Info(`a\nb`, "\nc\n")
+	want := `a\nb` + "\n" + DEFAULT_MULTILINE_MARKER + "c\n"
+	if got := h.String(); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
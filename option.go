@@ -1,5 +1,9 @@
 package log
 
+import (
+	"time"
+)
+
 type Option func(*Logger)
 
 func WithMinLevel(minLevel int) Option {
@@ -21,7 +25,200 @@ func WithTimeStyle(style FLAG_TIME) Option {
 }
 
 func WithColor(enable bool) Option {
+	return WithColorMode(ifs(enable, FLAG_COLOR_ALWAYS, FLAG_COLOR_NEVER))
+}
+
+// WithColorMode 设置颜色模式，默认 FLAG_COLOR_AUTO
+func WithColorMode(mode FLAG_COLOR) Option {
+	return func(l *Logger) {
+		l.colorMode = mode
+		l.resolveColor()
+	}
+}
+
+// WithCallerLevels 设置需要输出调用位置的日志级别
+func WithCallerLevels(levels ...int) Option {
+	return func(l *Logger) {
+		l.callerLevels = make(map[int]bool, len(levels))
+		for _, lv := range levels {
+			l.callerLevels[lv] = true
+		}
+	}
+}
+
+// WithCallerPath 设置调用位置的文件路径显示方式
+func WithCallerPath(mode FLAG_CALLER) Option {
+	return func(l *Logger) {
+		l.callerPath = mode
+	}
+}
+
+// WithStackTrace 为指定级别附加调用栈
+//
+// 参数中的 error 携带调用栈时优先输出该调用栈，maxDepth < 1 时不限制深度
+func WithStackTrace(maxDepth int, levels ...int) Option {
+	return func(l *Logger) {
+		l.stackDepth = maxDepth
+		l.stackLevels = make(map[int]bool, len(levels))
+		for _, lv := range levels {
+			l.stackLevels[lv] = true
+		}
+	}
+}
+
+// WithErrorChain 是否将参数中 error 的包装链(errors.Unwrap / errors.Join)展开为缩进树
+func WithErrorChain(enable bool) Option {
+	return func(l *Logger) {
+		l.errorChain = enable
+	}
+}
+
+// WithTimeLayout 使用自定义的时间格式，格式同 time.Format
+func WithTimeLayout(layout string) Option {
+	return func(l *Logger) {
+		l.flagTime = FLAG_TIME_LAYOUT
+		l.timeLayout = layout
+	}
+}
+
+// WithTimeLocation 设置时间的时区，eg: time.UTC
+func WithTimeLocation(loc *time.Location) Option {
+	return func(l *Logger) {
+		l.timeLocation = loc
+	}
+}
+
+// WithLineFormat 设置行格式，占位符见 DEFAULT_LINE_FORMAT，为空时使用 DEFAULT_LINE_FORMAT
+//
+// eg: "{time} [{level}] {msg}{fields} {caller}"
+func WithLineFormat(format string) Option {
+	lt := defaultLayout
+	if format != "" {
+		lt = compileLayout(format)
+	}
+	return func(l *Logger) {
+		l.layout = lt
+	}
+}
+
+// WithName 设置 Logger 名称，对应行格式中的 {name}
+func WithName(name string) Option {
+	return func(l *Logger) {
+		l.name = name
+	}
+}
+
+// WithTheme 设置颜色主题，内置 ThemeDark(默认)、ThemeLight、ThemeMonochrome
+func WithTheme(theme *Theme) Option {
+	return func(l *Logger) {
+		if theme == nil {
+			theme = ThemeDark
+		}
+		l.theme = theme
+	}
+}
+
+// WithStripANSI 未输出颜色时，是否移除内容中的 ANSI 转义序列(如 ColorWrap 的结果)，默认开启
+func WithStripANSI(enable bool) Option {
+	return func(l *Logger) {
+		l.stripANSI = enable
+	}
+}
+
+// WithPrettyPrint 为指定级别美化输出结构体、map、切片等参数
+//
+// maxDepth 最大展开层数，maxLen 切片、map 最多输出的元素个数，< 1 时使用默认值
+//
+// eg: WithPrettyPrint(0, 0, LV_DEBUG)
+func WithPrettyPrint(maxDepth, maxLen int, levels ...int) Option {
+	return func(l *Logger) {
+		l.prettyDepth = ifs(maxDepth < 1, defaultPrettyDepth, maxDepth)
+		l.prettyLen = ifs(maxLen < 1, defaultPrettyLen, maxLen)
+		l.prettyLevels = make(map[int]bool, len(levels))
+		for _, lv := range levels {
+			l.prettyLevels[lv] = true
+		}
+	}
+}
+
+// WithMultiline 设置内容中换行的处理方式(包括调用栈、错误链、GORM SQL)
+//
+// FLAG_MULTILINE_INDENT 模式下 marker 为续行的标记，为空时使用 DEFAULT_MULTILINE_MARKER
+func WithMultiline(mode FLAG_MULTILINE, marker string) Option {
+	return func(l *Logger) {
+		l.multiline = mode
+		l.multilineMarker = ifs(marker == "", DEFAULT_MULTILINE_MARKER, marker)
+	}
+}
+
+// WithMaxMessageBytes 内容(包括 GORM SQL)超过 n 字节时截断，n < 1 时不限制
+func WithMaxMessageBytes(n int) Option {
+	return func(l *Logger) {
+		l.maxMessageBytes = n
+	}
+}
+
+// WithMaxFieldBytes 字段值超过 n 字节时截断，n < 1 时不限制
+func WithMaxFieldBytes(n int) Option {
+	return func(l *Logger) {
+		l.maxFieldBytes = n
+	}
+}
+
+// WithRedactor 设置脱敏器，在输出之前脱敏参数、内容、字段及 GORM SQL
+func WithRedactor(redactor *Redactor) Option {
+	return func(l *Logger) {
+		l.redactor = redactor
+	}
+}
+
+// WithCallerSkip 额外跳过的调用层数，用于封装了 Logger 的函数报告其调用方
+func WithCallerSkip(skip int) Option {
+	return func(l *Logger) {
+		l.callerSkip = skip
+	}
+}
+
+// WithFileBuffer 为文件 handler 开启缓冲写入
+//
+// size 缓冲区大小(< 1 时 256K)，flushInterval > 0 时定时刷新，不低于 flushLevel 的日志写入后立即刷新；
+// 轮转、Close、Fatal、Panic 时也会刷新
+func WithFileBuffer(size int, flushInterval time.Duration, flushLevel int) Option {
+	return func(l *Logger) {
+		b, ok := findHandler[bufferable](l.handler)
+		if !ok {
+			return
+		}
+		b.setBuffer(size, flushInterval)
+		l.buffered = true
+		l.flushLevel = flushLevel
+	}
+}
+
+// WithAsync 使用无锁的异步 handler 包装当前 handler，见 NewAsyncHandler
+func WithAsync(maxPending int) Option {
+	return func(l *Logger) {
+		l.handler = NewAsyncHandler(l.handler, maxPending)
+	}
+}
+
+// WithSampling 使用采样 handler 包装当前 handler，限制重复日志的数量，见 NewSamplingHandler
+func WithSampling(interval time.Duration, first, thereafter int) Option {
+	return func(l *Logger) {
+		l.handler = NewSamplingHandler(l.handler, interval, first, thereafter)
+	}
+}
+
+// WithDedup 使用 handler 包装当前 handler，合并连续重复的日志，见 NewDedupHandler
+func WithDedup(timeout time.Duration) Option {
+	return func(l *Logger) {
+		l.handler = NewDedupHandler(l.handler, timeout)
+	}
+}
+
+// WithLimitKeys Every、FirstN、Once 最多记录的 key 数量(默认 1024)，超过时淘汰最久未使用的
+func WithLimitKeys(max int) Option {
 	return func(l *Logger) {
-		l.enableColor = enable
+		l.limits = newLimiter(max)
 	}
 }
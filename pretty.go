@@ -0,0 +1,317 @@
+package log
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// 美化输出的默认限制
+const (
+	defaultPrettyDepth = 10
+	defaultPrettyLen   = 100
+)
+
+// prettyValue 包装需要美化输出的参数，%v 时美化，其他动词按原样格式化
+type prettyValue struct {
+	l     *Logger
+	value any
+}
+
+func (p prettyValue) Format(f fmt.State, verb rune) {
+	if verb != 'v' {
+		fmt.Fprintf(f, fmt.FormatString(f, verb), p.value)
+		return
+	}
+	_, _ = f.Write(p.l.pretty(nil, p.value, false))
+}
+
+func (l *Logger) prettyArgs(args []any) []any {
+	result := make([]any, len(args))
+	for i, arg := range args {
+		result[i] = arg
+		if needPretty(arg) {
+			result[i] = prettyValue{l: l, value: arg}
+		}
+	}
+	return result
+}
+
+func needPretty(arg any) bool {
+	if arg == nil {
+		return false
+	}
+	switch arg.(type) {
+	case error, fmt.Stringer, fmt.Formatter:
+		return false
+	}
+	v := reflect.ValueOf(arg)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		return true
+	}
+	return false
+}
+
+// pretty 以多行缩进的形式输出，展开指针，map 按 key 排序，检测循环引用
+func (l *Logger) pretty(b []byte, value any, color bool) []byte {
+	p := &prettyPrinter{
+		b:        b,
+		color:    color,
+		theme:    l.theme,
+		maxDepth: l.prettyDepth,
+		maxLen:   l.prettyLen,
+		visited:  make(map[uintptr]bool),
+	}
+	p.print(reflect.ValueOf(value), 0)
+	return p.b
+}
+
+type prettyPrinter struct {
+	b        []byte
+	color    bool
+	theme    *Theme
+	maxDepth int
+	maxLen   int
+	// 当前路径上的指针，用于检测循环引用
+	visited map[uintptr]bool
+}
+
+var stringerType = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+func (p *prettyPrinter) scalar(str string, colors []COLOR_ENUM) {
+	if p.color {
+		str = colorize(str, colors)
+	}
+	p.b = append(p.b, str...)
+}
+
+func (p *prettyPrinter) newline(depth int) {
+	p.b = append(p.b, 0x0a)
+	for i := 0; i < depth; i++ {
+		p.b = append(p.b, "    "...)
+	}
+}
+
+func (p *prettyPrinter) print(v reflect.Value, depth int) {
+	if !v.IsValid() {
+		p.scalar("nil", p.theme.Nil)
+		return
+	}
+	if v.CanInterface() && v.Kind() != reflect.Ptr && v.Kind() != reflect.Interface {
+		switch {
+		case v.Type().Implements(errorType):
+			p.scalar(strconv.Quote(v.Interface().(error).Error()), p.theme.Error)
+			return
+		case v.Type().Implements(stringerType):
+			p.scalar(v.Interface().(fmt.Stringer).String(), p.theme.kindOf(v.Kind()))
+			return
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		p.scalar(strconv.FormatBool(v.Bool()), p.theme.Collection)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		p.scalar(strconv.FormatInt(v.Int(), 10), p.theme.Number)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		p.scalar(strconv.FormatUint(v.Uint(), 10), p.theme.Number)
+	case reflect.Float32, reflect.Float64:
+		p.scalar(strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), p.theme.Number)
+	case reflect.Complex64, reflect.Complex128:
+		p.scalar(strconv.FormatComplex(v.Complex(), 'g', -1, v.Type().Bits()), p.theme.Number)
+	case reflect.String:
+		p.scalar(strconv.Quote(v.String()), p.theme.String)
+	case reflect.Interface:
+		if v.IsNil() {
+			p.scalar("nil", p.theme.Nil)
+			return
+		}
+		p.print(v.Elem(), depth)
+	case reflect.Ptr:
+		if v.IsNil() {
+			p.scalar("nil", p.theme.Nil)
+			return
+		}
+		if p.visited[v.Pointer()] {
+			p.scalar("<cycle "+v.Type().String()+">", p.theme.Struct)
+			return
+		}
+		p.visited[v.Pointer()] = true
+		p.b = append(p.b, '&')
+		p.print(v.Elem(), depth)
+		delete(p.visited, v.Pointer())
+	case reflect.Struct:
+		p.printStruct(v, depth)
+	case reflect.Map:
+		p.printMap(v, depth)
+	case reflect.Slice, reflect.Array:
+		p.printList(v, depth)
+	default:
+		// chan, func, unsafe.Pointer
+		p.scalar(v.Type().String()+"("+fmt.Sprintf("%#x", v.Pointer())+")", p.theme.Struct)
+	}
+}
+
+func (p *prettyPrinter) printStruct(v reflect.Value, depth int) {
+	t := v.Type()
+	p.b = append(p.b, t.String()...)
+	if v.NumField() == 0 {
+		p.b = append(p.b, "{}"...)
+		return
+	}
+	if depth >= p.maxDepth {
+		p.b = append(p.b, "{...}"...)
+		return
+	}
+	p.b = append(p.b, '{')
+	for i := 0; i < v.NumField(); i++ {
+		p.newline(depth + 1)
+		p.b = append(p.b, t.Field(i).Name...)
+		p.b = append(p.b, ": "...)
+		p.print(v.Field(i), depth+1)
+		p.b = append(p.b, ',')
+	}
+	p.newline(depth)
+	p.b = append(p.b, '}')
+}
+
+func (p *prettyPrinter) printList(v reflect.Value, depth int) {
+	p.b = append(p.b, v.Type().String()...)
+	if v.Kind() == reflect.Slice {
+		if v.IsNil() {
+			p.b = append(p.b, "(nil)"...)
+			return
+		}
+		if v.Len() > 0 {
+			if p.visited[v.Pointer()] {
+				p.b = append(p.b, "{<cycle>}"...)
+				return
+			}
+			p.visited[v.Pointer()] = true
+			defer delete(p.visited, v.Pointer())
+		}
+	}
+	if v.Type().Elem().Kind() == reflect.Uint8 {
+		// []byte 整体输出为带引号的字符串
+		data := make([]byte, v.Len())
+		for i := range data {
+			data[i] = byte(v.Index(i).Uint())
+		}
+		p.b = append(p.b, '(')
+		p.scalar(strconv.Quote(string(data)), p.theme.String)
+		p.b = append(p.b, ')')
+		return
+	}
+	if v.Len() == 0 {
+		p.b = append(p.b, "{}"...)
+		return
+	}
+	if depth >= p.maxDepth {
+		p.b = append(p.b, "{...}"...)
+		return
+	}
+	p.b = append(p.b, '{')
+	for i := 0; i < v.Len(); i++ {
+		p.newline(depth + 1)
+		if i >= p.maxLen {
+			p.b = append(p.b, "... ("...)
+			p.b = strconv.AppendInt(p.b, int64(v.Len()-i), 10)
+			p.b = append(p.b, " more)"...)
+			break
+		}
+		p.print(v.Index(i), depth+1)
+		p.b = append(p.b, ',')
+	}
+	p.newline(depth)
+	p.b = append(p.b, '}')
+}
+
+func (p *prettyPrinter) printMap(v reflect.Value, depth int) {
+	p.b = append(p.b, v.Type().String()...)
+	if v.IsNil() {
+		p.b = append(p.b, "(nil)"...)
+		return
+	}
+	if v.Len() == 0 {
+		p.b = append(p.b, "{}"...)
+		return
+	}
+	if p.visited[v.Pointer()] {
+		p.b = append(p.b, "{<cycle>}"...)
+		return
+	}
+	if depth >= p.maxDepth {
+		p.b = append(p.b, "{...}"...)
+		return
+	}
+	p.visited[v.Pointer()] = true
+	defer delete(p.visited, v.Pointer())
+
+	// 同 fmt，数字按大小、字符串按字典序排序，其他类型按无颜色的输出排序
+	type entry struct {
+		key  string
+		k, v reflect.Value
+	}
+	entries := make([]entry, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		sub := &prettyPrinter{theme: p.theme, maxDepth: 1, maxLen: p.maxLen, visited: map[uintptr]bool{}}
+		sub.print(iter.Key(), 0)
+		entries = append(entries, entry{key: string(sub.b), k: iter.Key(), v: iter.Value()})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if less, ok := lessMapKey(entries[i].k, entries[j].k); ok {
+			return less
+		}
+		return entries[i].key < entries[j].key
+	})
+
+	p.b = append(p.b, '{')
+	for i, e := range entries {
+		p.newline(depth + 1)
+		if i >= p.maxLen {
+			p.b = append(p.b, "... ("...)
+			p.b = strconv.AppendInt(p.b, int64(len(entries)-i), 10)
+			p.b = append(p.b, " more)"...)
+			break
+		}
+		p.print(e.k, depth+1)
+		p.b = append(p.b, ": "...)
+		p.print(e.v, depth+1)
+		p.b = append(p.b, ',')
+	}
+	p.newline(depth)
+	p.b = append(p.b, '}')
+}
+
+// lessMapKey 比较同类的数字、字符串及布尔类型的 key，其他类型返回 false
+func lessMapKey(a, b reflect.Value) (less, ok bool) {
+	if a.Kind() == reflect.Interface {
+		a = a.Elem()
+	}
+	if b.Kind() == reflect.Interface {
+		b = b.Elem()
+	}
+	if !a.IsValid() || !b.IsValid() || a.Kind() != b.Kind() {
+		return false, false
+	}
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() < b.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() < b.Uint(), true
+	case reflect.Float32, reflect.Float64:
+		return a.Float() < b.Float(), true
+	case reflect.String:
+		return a.String() < b.String(), true
+	case reflect.Bool:
+		return !a.Bool() && b.Bool(), true
+	}
+	return false, false
+}
@@ -0,0 +1,25 @@
+package log
+
+import (
+	"testing"
+)
+
+func TestPrettyMapKeyOrder(t *testing.T) {
+	logger, h := newTestLogger(WithPrettyPrint(0, 0, LV_DEBUG))
+	logger.Debug(map[int]string{10: "c", 2: "b", 1: "a"})
+	logger.Debug(map[string]int{"b": 2, "a": 1, "B": 3})
+	want := "map[int]string{\n    1: \"a\",\n    2: \"b\",\n    10: \"c\",\n}\n" +
+		"map[string]int{\n    \"B\": 3,\n    \"a\": 1,\n    \"b\": 2,\n}\n"
+	if got := h.String(); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestPrettyBytes(t *testing.T) {
+	logger, h := newTestLogger(WithPrettyPrint(0, 0, LV_DEBUG))
+	logger.Debug([]byte("hi\n"), [2]byte{'o', 'k'})
+	want := "[]uint8(\"hi\\n\") [2]uint8(\"ok\")\n"
+	if got := h.String(); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
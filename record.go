@@ -0,0 +1,111 @@
+package log
+
+import "time"
+
+// Caller 调用位置，未开启该级别的调用位置时为空，见 WithCallerLevels
+type Caller struct {
+	File string
+	Line int
+	Func string
+}
+
+// Record 一条日志
+//
+// TimeText、Message、Fields、ErrorChain、Line 只在 Handle 调用期间有效，需要保留时应复制
+type Record struct {
+	Level int
+	// 已转换到 WithTimeLocation 设置的时区
+	Time time.Time
+	// 按照 WithTimeStyle、WithTimeLayout 格式化的时间，不输出时间时为空
+	TimeText []byte
+	Caller   Caller
+	// Logger 的名称，见 WithName
+	Name string
+	// 去除颜色的消息内容
+	Message []byte
+	// 与文本输出相同规则处理(去除颜色、脱敏、截断)后的字段，见 WithRedactor、WithMaxFieldBytes
+	Fields []Field
+	// 开启 WithErrorChain 时参数中 error 的包装链，每层一项
+	ErrorChain []string
+	// 输出的完整一行(含换行符)
+	Line []byte
+}
+
+// RecordHandler 需要日志级别等信息的 handler
+//
+// 传给 New 的 handler 实现该接口时，Logger 会调用 Handle 代替 Write，并通过 Enabled 跳过不需要的日志
+type RecordHandler interface {
+	Handle(r Record) error
+	Enabled(level int) bool
+}
+
+// lineHandler 只写入 Record.Line 的 RecordHandler，包装它的 handler(如异步 handler)可以合并多行写入
+type lineHandler interface {
+	lineOnly()
+}
+
+// recordAdapter 将 IHandler 适配为 RecordHandler，或将 RecordHandler 适配为 IHandler
+type recordAdapter struct {
+	handler IHandler
+	record  RecordHandler
+}
+
+func (a *recordAdapter) Handle(r Record) error {
+	if a.record != nil {
+		return a.record.Handle(r)
+	}
+	_, err := a.handler.Write(r.Line)
+	return err
+}
+
+func (a *recordAdapter) Enabled(level int) bool {
+	return a.record == nil || a.record.Enabled(level)
+}
+
+// Write 没有级别信息时以 LV_PRINT 交给 RecordHandler
+func (a *recordAdapter) Write(b []byte) (n int, err error) {
+	if a.record != nil {
+		return len(b), a.record.Handle(Record{Level: LV_PRINT, Time: time.Now(), Line: b})
+	}
+	return a.handler.Write(b)
+}
+
+func (a *recordAdapter) Close() (err error) {
+	if a.record == nil {
+		return a.handler.Close()
+	}
+	if c, ok := a.record.(interface{ Close() error }); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+func (a *recordAdapter) Flush() (err error) {
+	if f, ok := a.record.(IFlusher); ok {
+		return f.Flush()
+	}
+	if f, ok := findHandler[IFlusher](a.handler); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+func (a *recordAdapter) Unwrap() IHandler {
+	return a.handler
+}
+
+// AsRecordHandler 将 IHandler 适配为 RecordHandler，Handle 写入 Record.Line
+func AsRecordHandler(handler IHandler) RecordHandler {
+	if rh, ok := handler.(RecordHandler); ok {
+		return rh
+	}
+	return &recordAdapter{handler: handler}
+}
+
+// NewRecordLogger 使用只实现了 RecordHandler 的 handler 创建 Logger
+func NewRecordLogger(handler RecordHandler, opts ...Option) *Logger {
+	if h, ok := handler.(IHandler); ok {
+		return New(h, opts...)
+	}
+	return New(&recordAdapter{record: handler}, opts...)
+}
@@ -0,0 +1,184 @@
+package log
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// 默认的脱敏掩码
+const REDACT_MASK = "***"
+
+// 默认需要脱敏的字段名，字段名(忽略大小写)包含其中任意一个即脱敏
+var DEFAULT_REDACT_FIELDS = []string{"password", "passwd", "secret", "token", "authorization", "api_key", "apikey"}
+
+// RedactRule 正则脱敏规则，Replace 为空时整体替换为掩码
+type RedactRule struct {
+	Name    string
+	Pattern *regexp.Regexp
+	Replace func(match string) string
+}
+
+var (
+	REDACT_JWT = RedactRule{
+		Name:    "jwt",
+		Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`),
+	}
+	REDACT_BEARER = RedactRule{
+		Name:    "bearer",
+		Pattern: regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9._~+/=-]+`),
+		Replace: func(match string) string {
+			return match[:strings.IndexAny(match, " \t")+1] + REDACT_MASK
+		},
+	}
+	REDACT_CARD = RedactRule{
+		Name:    "card",
+		Pattern: regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+		Replace: func(match string) string {
+			if !luhnValid(match) {
+				return match
+			}
+			return REDACT_MASK
+		},
+	}
+	REDACT_EMAIL = RedactRule{
+		Name:    "email",
+		Pattern: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+		Replace: func(match string) string {
+			return REDACT_MASK + match[strings.LastIndexByte(match, '@'):]
+		},
+	}
+)
+
+// Redactor 脱敏器，作用于参数、内容、字段及 GORM SQL
+type Redactor struct {
+	// 字段名黑名单，同时用于匹配内容中的 key=value、key: value
+	fields []string
+	rules  []RedactRule
+	// Hook 在格式化之前替换参数及字段值，eg: 将某类型替换为 Redacted
+	Hook func(value any) any
+}
+
+// NewRedactor 创建脱敏器，fields 为空时使用 DEFAULT_REDACT_FIELDS
+//
+// eg: NewRedactor(nil, REDACT_JWT, REDACT_BEARER, REDACT_CARD, REDACT_EMAIL)
+func NewRedactor(fields []string, rules ...RedactRule) *Redactor {
+	if len(fields) == 0 {
+		fields = DEFAULT_REDACT_FIELDS
+	}
+	r := &Redactor{rules: append([]RedactRule(nil), rules...)}
+	quoted := make([]string, 0, len(fields))
+	for _, field := range fields {
+		r.fields = append(r.fields, strings.ToLower(field))
+		quoted = append(quoted, regexp.QuoteMeta(field))
+	}
+	// 内容中的 key=value: password=xxx、"token": "xxx"、password = 'xxx'、Authorization: Bearer xxx，放在自定义规则之后
+	kv := regexp.MustCompile(`(?i)(\w*(?:` + strings.Join(quoted, "|") + `)\w*["']?\s*[=:]\s*)("[^"]*"|'[^']*'|(?:(?:bearer|basic|digest|token)\s+)?[^\s,;&)]+)`)
+	r.rules = append(r.rules, RedactRule{
+		Name:    "field",
+		Pattern: kv,
+		Replace: func(match string) string {
+			return kv.ReplaceAllString(match, "${1}"+REDACT_MASK)
+		},
+	})
+	return r
+}
+
+// IsSecretField 字段名是否在黑名单中
+func (r *Redactor) IsSecretField(key string) bool {
+	key = strings.ToLower(key)
+	for _, field := range r.fields {
+		if strings.Contains(key, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactString 按照规则脱敏文本
+func (r *Redactor) RedactString(s string) string {
+	for _, rule := range r.rules {
+		if !rule.Pattern.MatchString(s) {
+			continue
+		}
+		if rule.Replace == nil {
+			s = rule.Pattern.ReplaceAllLiteralString(s, REDACT_MASK)
+		} else {
+			s = rule.Pattern.ReplaceAllStringFunc(s, rule.Replace)
+		}
+	}
+	return s
+}
+
+// redactBytes 脱敏 b[start:]
+func (r *Redactor) redactBytes(b []byte, start int) []byte {
+	for _, rule := range r.rules {
+		if !rule.Pattern.Match(b[start:]) {
+			continue
+		}
+		var replaced []byte
+		if rule.Replace == nil {
+			replaced = rule.Pattern.ReplaceAllLiteral(b[start:], []byte(REDACT_MASK))
+		} else {
+			replaced = rule.Pattern.ReplaceAllFunc(b[start:], func(match []byte) []byte {
+				return []byte(rule.Replace(string(match)))
+			})
+		}
+		b = append(b[:start], replaced...)
+	}
+	return b
+}
+
+func (r *Redactor) hookArgs(args []any) []any {
+	if r.Hook == nil {
+		return args
+	}
+	result := make([]any, len(args))
+	for i, arg := range args {
+		result[i] = r.Hook(arg)
+	}
+	return result
+}
+
+// Redacted 包装敏感值，任何格式化动词都只输出掩码
+//
+// eg: log.Infof("login %s %v", user, log.Redacted{Value: password})
+type Redacted struct {
+	Value any
+}
+
+func (r Redacted) String() string {
+	return REDACT_MASK
+}
+
+func (r Redacted) GoString() string {
+	return REDACT_MASK
+}
+
+func (r Redacted) Format(f fmt.State, verb rune) {
+	_, _ = f.Write([]byte(REDACT_MASK))
+}
+
+func (r Redacted) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + REDACT_MASK + `"`), nil
+}
+
+func luhnValid(number string) bool {
+	sum, n := 0, 0
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n >= 13 && sum%10 == 0
+}
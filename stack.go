@@ -0,0 +1,106 @@
+package log
+
+import (
+	"errors"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// 采集调用栈时的最大帧数
+const maxStackFrames = 64
+
+type callersError interface {
+	Callers() []uintptr
+}
+
+// ErrorStack 获取错误链中最内层携带的调用栈
+//
+// 支持 Callers() []uintptr 以及 pkg/errors 风格的 StackTrace() 方法
+func ErrorStack(err error) []uintptr {
+	var pcs []uintptr
+	for err != nil && !isNilError(err) {
+		if s := stackOf(err); len(s) > 0 {
+			pcs = s
+		}
+		err = errors.Unwrap(err)
+	}
+	return pcs
+}
+
+// isNilError 是否为值为 nil 指针等的 error，调用其方法可能 panic(同 fmt，输出为 <nil>)
+func isNilError(err error) bool {
+	if err == nil {
+		return true
+	}
+	v := reflect.ValueOf(err)
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+func stackOf(err error) []uintptr {
+	if c, ok := err.(callersError); ok {
+		return c.Callers()
+	}
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return nil
+	}
+	out := m.Call(nil)[0]
+	if out.Kind() != reflect.Slice || out.Type().Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+	pcs := make([]uintptr, out.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(out.Index(i).Uint())
+	}
+	return pcs
+}
+
+func (l *Logger) withStack(lv int, buf *writePool, args []any) {
+	if !l.stackLevels[lv] {
+		return
+	}
+	var pcs []uintptr
+	for _, arg := range args {
+		if err, ok := arg.(error); ok {
+			if pcs = ErrorStack(err); len(pcs) > 0 {
+				break
+			}
+		}
+	}
+	if len(pcs) == 0 {
+		var stack [maxStackFrames]uintptr
+		n := runtime.Callers(3, stack[:])
+		pcs = stack[:n]
+	}
+	buf.buffer = l.appendStack(buf.buffer, pcs)
+}
+
+func (l *Logger) appendStack(b []byte, pcs []uintptr) []byte {
+	frames := runtime.CallersFrames(pcs)
+	depth := 0
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" && !strings.HasPrefix(frame.Function, packagePrefix) && frame.Function != "runtime.goexit" {
+			if l.stackDepth > 0 && depth >= l.stackDepth {
+				break
+			}
+			b = append(b, "\n\t"...)
+			b = append(b, frame.Function...)
+			b = append(b, "\n\t\t"...)
+			b = append(b, FormatFileName(frame.File, l.callerPath)...)
+			b = append(b, ':')
+			b = strconv.AppendInt(b, int64(frame.Line), 10)
+			depth++
+		}
+		if !more {
+			break
+		}
+	}
+	return b
+}
@@ -0,0 +1,54 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// bufferHandler 将日志写入内存，供测试检查输出
+type bufferHandler struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (h *bufferHandler) Write(b []byte) (n int, err error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return h.buf.Write(b)
+}
+
+func (h *bufferHandler) Close() error {
+	return nil
+}
+
+func (h *bufferHandler) String() string {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return h.buf.String()
+}
+
+// newTestLogger 不输出颜色，默认只输出消息及字段
+func newTestLogger(opts ...Option) (*Logger, *bufferHandler) {
+	h := &bufferHandler{}
+	opts = append([]Option{WithColorMode(FLAG_COLOR_NEVER), WithLineFormat("{msg}{fields}")}, opts...)
+	return New(h, opts...), h
+}
+
+type nilPointerError struct {
+	inner error
+}
+
+func (e *nilPointerError) Error() string         { return "wrapped: " + e.inner.Error() }
+func (e *nilPointerError) Unwrap() error         { return e.inner }
+func (e *nilPointerError) StackTrace() []uintptr { return nil }
+
+func TestStackTraceTypedNilError(t *testing.T) {
+	logger, h := newTestLogger(WithStackTrace(0, LV_ERROR))
+	var err *nilPointerError
+	logger.Error("x", err)
+	if got := h.String(); !strings.HasPrefix(got, "x<nil>\n") {
+		t.Fatalf("got %q", got)
+	}
+}
@@ -0,0 +1,116 @@
+package log
+
+import (
+	"reflect"
+)
+
+// Theme 颜色主题，颜色为空时不着色
+type Theme struct {
+	// 级别颜色，未配置的级别使用 LV_ATTRS 中的颜色
+	Levels map[int][]COLOR_ENUM
+
+	// 按参数类型着色
+	Nil        []COLOR_ENUM
+	Error      []COLOR_ENUM
+	String     []COLOR_ENUM
+	Number     []COLOR_ENUM // 整数、浮点数、复数、指针
+	Collection []COLOR_ENUM // bool、数组、切片、map
+	Struct     []COLOR_ENUM // 结构体、chan、函数、接口
+
+	// 调用位置: 文件:行号 及函数名
+	CallerFile []COLOR_ENUM
+	CallerFunc []COLOR_ENUM
+
+	Time     []COLOR_ENUM
+	FieldKey []COLOR_ENUM
+	SQL      []COLOR_ENUM
+}
+
+var (
+	// 深色背景(默认)
+	ThemeDark = &Theme{
+		Nil:        []COLOR_ENUM{COLOR_FG_BLUE},
+		Error:      []COLOR_ENUM{COLOR_FG_RED},
+		Number:     []COLOR_ENUM{COLOR_FG_MAGENTA},
+		Collection: []COLOR_ENUM{COLOR_FG_CYAN},
+		Struct:     []COLOR_ENUM{COLOR_FG_BLUE},
+		CallerFile: []COLOR_ENUM{COLOR_FG_YELLOW, COLOR_CTRL_UNDERLINE},
+		CallerFunc: []COLOR_ENUM{COLOR_FG_RED},
+		FieldKey:   []COLOR_ENUM{COLOR_FG_GREEN},
+		SQL:        []COLOR_ENUM{COLOR_FG_CYAN},
+	}
+
+	// 浅色背景
+	ThemeLight = &Theme{
+		Levels: map[int][]COLOR_ENUM{
+			LV_DEBUG: {Color256(240), COLOR_CTRL_BOLD},
+			LV_PRINT: {Color256(30), COLOR_CTRL_BOLD},
+			LV_INFO:  {Color256(25), COLOR_CTRL_BOLD},
+			LV_WARN:  {Color256(130), COLOR_CTRL_BOLD},
+			LV_ERROR: {Color256(160), COLOR_CTRL_BOLD},
+			LV_PANIC: {Color256(90), COLOR_CTRL_BOLD},
+			LV_FATAL: {Color256(90), COLOR_CTRL_BOLD},
+		},
+		Nil:        []COLOR_ENUM{Color256(25)},
+		Error:      []COLOR_ENUM{Color256(160)},
+		Number:     []COLOR_ENUM{Color256(90)},
+		Collection: []COLOR_ENUM{Color256(30)},
+		Struct:     []COLOR_ENUM{Color256(25)},
+		Time:       []COLOR_ENUM{Color256(244)},
+		CallerFile: []COLOR_ENUM{Color256(94), COLOR_CTRL_UNDERLINE},
+		CallerFunc: []COLOR_ENUM{Color256(160)},
+		FieldKey:   []COLOR_ENUM{Color256(28)},
+		SQL:        []COLOR_ENUM{Color256(30)},
+	}
+
+	// 单色，仅使用粗体、下划线
+	ThemeMonochrome = &Theme{
+		Levels: map[int][]COLOR_ENUM{
+			LV_DEBUG: {COLOR_CTRL_BOLD},
+			LV_PRINT: {COLOR_CTRL_BOLD},
+			LV_INFO:  {COLOR_CTRL_BOLD},
+			LV_WARN:  {COLOR_CTRL_BOLD},
+			LV_ERROR: {COLOR_CTRL_BOLD},
+			LV_PANIC: {COLOR_CTRL_BOLD, COLOR_CTRL_REVERSE},
+			LV_FATAL: {COLOR_CTRL_BOLD, COLOR_CTRL_REVERSE},
+		},
+		Error:      []COLOR_ENUM{COLOR_CTRL_BOLD},
+		CallerFile: []COLOR_ENUM{COLOR_CTRL_UNDERLINE},
+	}
+)
+
+func (t *Theme) level(lv int) []COLOR_ENUM {
+	if colors, ok := t.Levels[lv]; ok {
+		return colors
+	}
+	return LV_ATTRS[lv].Color
+}
+
+// kind 按照参数类型获取颜色
+func (t *Theme) kind(arg any) []COLOR_ENUM {
+	if arg == nil {
+		return t.Nil
+	}
+	if _, ok := arg.(error); ok {
+		return t.Error
+	}
+	return t.kindOf(reflect.ValueOf(arg).Kind())
+}
+
+func (t *Theme) kindOf(kind reflect.Kind) []COLOR_ENUM {
+	switch kind {
+	case reflect.String:
+		return t.String
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Ptr, reflect.Uintptr,
+		reflect.Complex64, reflect.Complex128:
+		return t.Number
+	case reflect.Bool, reflect.Array, reflect.Slice, reflect.Map:
+		return t.Collection
+	case reflect.Struct, reflect.Chan, reflect.Func, reflect.Interface:
+		return t.Struct
+	default:
+		return nil
+	}
+}
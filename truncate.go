@@ -0,0 +1,152 @@
+package log
+
+import (
+	"bytes"
+	"strconv"
+	"unicode/utf8"
+)
+
+// 设置了脱敏器时，渲染消息额外保留的字节数，见 withMessage
+const redactMargin = 4096
+
+// 超过该容量的缓冲区不放回 sync.Pool，避免偶发的大日志长期占用内存
+const maxPoolBufferSize = 64 * 1024
+
+func (l *Logger) putBuffer(buf *writePool) {
+	if cap(buf.buffer) > maxPoolBufferSize || cap(buf.message) > maxPoolBufferSize {
+		return
+	}
+	for i := range buf.fields {
+		buf.fields[i] = Field{}
+	}
+	buf.fields = buf.fields[:0]
+	for i := range buf.chain {
+		buf.chain[i] = ""
+	}
+	buf.chain = buf.chain[:0]
+	l.pool.Put(buf)
+}
+
+// truncateBytes 将 b[start:] 截断到 max 个可见字节(不计 ANSI 转义序列)，并追加截断标记，dropped 为渲染时已丢弃的字节数
+func (l *Logger) truncateBytes(b []byte, start, max, dropped int) []byte {
+	if max < 1 {
+		return b
+	}
+	cut, kept, total := visibleIndex(b[start:], max)
+	if total <= max && dropped == 0 {
+		return b
+	}
+	dropped += total - kept
+	b = b[:start+cut]
+	if l.enableColor && bytes.IndexByte(b[start:], 0x1b) > -1 {
+		b = append(b, COLOR_CTRL_RESET...)
+	}
+	return appendTruncated(b, dropped)
+}
+
+// visibleIndex 获取保留不超过 max 个可见字节的截断位置，不截断 UTF-8 字符及 ANSI 转义序列
+//
+// kept 为截断位置之前的可见字节数，total 为全部的可见字节数
+func visibleIndex(b []byte, max int) (cut, kept, total int) {
+	cut = -1
+	for i := 0; i < len(b); {
+		if b[i] == 0x1b {
+			i += ansiSeqLen(b[i:])
+			continue
+		}
+		_, size := utf8.DecodeRune(b[i:])
+		if cut < 0 && kept+size > max {
+			cut = i
+		}
+		if cut < 0 {
+			kept += size
+		}
+		total += size
+		i += size
+	}
+	if cut < 0 {
+		cut = len(b)
+	}
+	return cut, kept, total
+}
+
+// boundedWriter 渲染消息时最多写入到 limit，超出的部分只计数
+type boundedWriter struct {
+	b       []byte
+	limit   int
+	dropped int
+}
+
+func (w *boundedWriter) Write(p []byte) (n int, err error) {
+	n = len(p)
+	if room := w.limit - len(w.b); room < len(p) {
+		if room < 0 {
+			room = 0
+		}
+		w.dropped += len(p) - room
+		p = p[:room]
+	}
+	w.b = append(w.b, p...)
+	return n, nil
+}
+
+// boundArgs 将超过 max 字节的字符串参数截短后再渲染，避免先格式化完整内容，返回截掉的字节数
+//
+// 保留 max+1 字节，使渲染结果仍超过 max 从而追加截断标记；需要截短时复制 args，不修改调用方的切片
+func boundArgs(args []any, max int) ([]any, int) {
+	var bounded []any
+	chopped := 0
+	for i, arg := range args {
+		var size int
+		switch v := arg.(type) {
+		case string:
+			size = len(v)
+		case []byte:
+			size = len(v)
+		}
+		if size <= max+1 {
+			continue
+		}
+		if bounded == nil {
+			bounded = append([]any(nil), args...)
+		}
+		switch v := arg.(type) {
+		case string:
+			bounded[i] = v[:max+1]
+		case []byte:
+			bounded[i] = v[:max+1]
+		}
+		chopped += size - max - 1
+	}
+	if bounded == nil {
+		return args, 0
+	}
+	return bounded, chopped
+}
+
+// truncateString 将 s 截断到 max 字节，并追加截断标记
+func truncateString(s string, max int) string {
+	if max < 1 || len(s) <= max {
+		return s
+	}
+	cut := truncateIndex([]byte(s), max)
+	return string(appendTruncated([]byte(s[:cut]), len(s)-cut))
+}
+
+// truncateIndex 获取不超过 max 的截断位置，不截断 UTF-8 字符及 ANSI 转义序列
+func truncateIndex(b []byte, max int) int {
+	cut := max
+	for cut > 0 && !utf8.RuneStart(b[cut]) {
+		cut--
+	}
+	if esc := bytes.LastIndexByte(b[:cut], 0x1b); esc > -1 && bytes.IndexByte(b[esc:cut], 'm') < 0 {
+		cut = esc
+	}
+	return cut
+}
+
+func appendTruncated(b []byte, dropped int) []byte {
+	b = append(b, "…(truncated "...)
+	b = strconv.AppendInt(b, int64(dropped), 10)
+	return append(b, " bytes)"...)
+}
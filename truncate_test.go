@@ -0,0 +1,43 @@
+package log
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTruncateRedactsAcrossCut(t *testing.T) {
+	logger, h := newTestLogger(WithRedactor(NewRedactor(nil, REDACT_CARD)), WithMaxMessageBytes(16))
+	logger.Info("pay 4111111111111111 done")
+	if got := h.String(); strings.Contains(got, "4111") || !strings.HasPrefix(got, "pay *** done") {
+		t.Fatalf("card number leaked: %q", got)
+	}
+
+	logger, h = newTestLogger(WithRedactor(NewRedactor(nil, REDACT_CARD)), WithMaxMessageBytes(8))
+	logger.Infof("pay %s", "4111111111111111 "+strings.Repeat("x", 10000))
+	if got := h.String(); strings.Contains(got, "4111") {
+		t.Fatalf("card number leaked: %q", got)
+	}
+}
+
+func TestTruncateLargeArgument(t *testing.T) {
+	logger, h := newTestLogger(WithMaxMessageBytes(10))
+	logger.Info(strings.Repeat("x", 1<<20))
+	want := "xxxxxxxxxx…(truncated 1048566 bytes)\n"
+	if got := h.String(); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestTruncateColorCountsVisibleBytes(t *testing.T) {
+	plain, h1 := newTestLogger(WithMaxMessageBytes(10))
+	colored, h2 := newTestLogger(WithMaxMessageBytes(10), WithColorMode(FLAG_COLOR_ALWAYS))
+	plain.Info(12345678901234, 5)
+	colored.Info(12345678901234, 5)
+	want := "1234567890…(truncated 6 bytes)\n"
+	if got := h1.String(); got != want {
+		t.Fatalf("plain: got %q, want %q", got, want)
+	}
+	if got := StripANSIString(h2.String()); got != want {
+		t.Fatalf("colored: got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,24 @@
+//go:build linux
+
+package log
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// isTerminal 通过 ioctl(TCGETS) 判断文件是否为终端
+func isTerminal(f *os.File) bool {
+	conn, err := f.SyscallConn()
+	if err != nil {
+		return false
+	}
+	var ok bool
+	_ = conn.Control(func(fd uintptr) {
+		var termios syscall.Termios
+		_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TCGETS, uintptr(unsafe.Pointer(&termios)))
+		ok = errno == 0
+	})
+	return ok
+}
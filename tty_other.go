@@ -0,0 +1,16 @@
+//go:build !linux
+
+package log
+
+import (
+	"os"
+)
+
+// isTerminal 非 linux 平台退化为判断是否为字符设备
+func isTerminal(f *os.File) bool {
+	stat, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return stat.Mode()&os.ModeCharDevice != 0
+}